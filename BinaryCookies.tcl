@@ -49,7 +49,10 @@ for {set pageIndex 0} {$pageIndex < $size} {incr pageIndex} {
 }
 
 section "Footer" {
-	bytes 8 "Checksum"
+	big_endian
+	uint32 "Checksum"
+	requires [pos] "07 17 20 05"
+	bytes 4 "Footer"
 
 	# Additional bytes are part of an optional bplist dictionary that usually
 	# contains a dictionary with a key NSHTTPCookieAcceptPolicy and a value
@@ -13,24 +13,58 @@ var magic []byte = []byte{0x63, 0x6f, 0x6f, 0x6b}
 // timePadding is the Unix timestamp until Jan 2001 when Mac epoch starts.
 var timePadding float64 = 978307200
 
+// minTime and maxTime are the first second of year 1 and the last second of
+// year 9999 in Mac epoch time, the range of valid cookie timestamps.
+const (
+	minTime = -63113904000
+	maxTime = 252423993599
+)
+
 // BinaryCookies is a struct representing relevant parts of the binary cookies
 // archive. A couple of methods are available to read and validate the archive
 // and to extract relevant information.
 type BinaryCookies struct {
-	file     io.Reader
-	size     uint32
-	page     []uint32
-	pages    []Page
-	checksum []byte
+	file      io.Reader
+	input     []byte
+	zeroCopy  bool
+	size      uint32
+	page      []uint32
+	pages     []Page
+	checksum  uint32
+	computed  uint32
+	trailer   []byte
+	policy    *Policy
+	offset    int64
+	pageStart int64
+	paging    bool
+	opts      DecoderOptions
+	errors    []*DecodeError
+	scan      scanner
+
+	pageIndex   int
+	cookieIndex int
 }
 
 // Page represents a single web page and contains all the cookies associated
 // to the same domain. A binary cookies archive contains all the cookies for
 // all the pages the user has ever visited.
+//
+// Start, Size and End are populated by the decoder with the position of the
+// page in the file, the size declared in the page size table, and the position
+// right after the page. The encoder ignores them.
+//
+// Orphans are the old cookies found in the unused bytes of the page when the
+// RecoverSlack option is set. They are not referenced by the cookie offsets,
+// so the encoder writes them back as unused bytes, not as cookies.
 type Page struct {
 	Length  uint32
 	Offsets []uint32
 	Cookies []Cookie
+	Orphans []Cookie
+	Start   int64
+	Size    uint32
+	End     int64
+	slack   []byte
 }
 
 // Cookie or HTTP cookie is a small piece of data sent from a website and
@@ -47,18 +81,27 @@ type Page struct {
 // receives and sends back unchanged, used by Unix programmers.
 //
 // Ref: https://en.wikipedia.org/wiki/HTTP_cookie
+//
+// The string offsets are relative to the beginning of the cookie, and Start
+// and End are the position of the cookie in the file and the position right
+// after it. These fields are populated by the decoder, the encoder computes
+// the offsets again and ignores the positions.
+//
+// Orphaned means the cookie is not referenced by the page, it was recovered
+// from the unused bytes between cookies or at the end of the page, usually
+// left behind when WebKit rewrites the page.
 type Cookie struct {
 	Size          uint32
 	unknownOne    []byte
-	Flags         uint32
+	Flags         Flags
 	Secure        bool
 	HttpOnly      bool
 	unknownTwo    []byte
-	domainOffset  uint32
-	nameOffset    uint32
-	pathOffset    uint32
-	valueOffset   uint32
-	commentOffset uint32
+	DomainOffset  uint32
+	NameOffset    uint32
+	PathOffset    uint32
+	ValueOffset   uint32
+	CommentOffset uint32
 	Comment       []byte
 	Domain        []byte
 	Name          []byte
@@ -66,6 +109,11 @@ type Cookie struct {
 	Value         []byte
 	Expires       time.Time
 	Creation      time.Time
+	Start         int64
+	End           int64
+	Orphaned      bool
+	hasComment    bool
+	slack         []byte
 }
 
 func (c Cookie) String() string {
@@ -92,6 +140,13 @@ func (c Cookie) String() string {
 	return buf.String()
 }
 
+// HostOnly reports whether the cookie is only sent to the host in the domain
+// and not to its subdomains, which is the case when the domain does not start
+// with a dot. The domain is the only source of this information.
+func (c Cookie) HostOnly() bool {
+	return !bytes.HasPrefix(c.Domain, []byte("."))
+}
+
 // cookieHelperFunction defines a function signature to help readPageCookie
 // read and process all the bytes associated to all the cookies found on each
 // page. Each function takes a cookie pointer, allocates certain amount of
@@ -99,11 +154,54 @@ func (c Cookie) String() string {
 // decoded bytes into the cookie.
 type cookieHelperFunction func(*Cookie) error
 
+// DisallowInvalidChecksum causes Decode to return ErrChecksum when the checksum
+// stored in the file does not match the checksum of the pages, which usually
+// means the file was truncated or modified by something other than WebKit.
+// It is the same as setting DecoderOptions.DisallowInvalidChecksum.
+func (b *BinaryCookies) DisallowInvalidChecksum() {
+	b.opts.DisallowInvalidChecksum = true
+}
+
+// RecoverCorrupted causes Decode to skip pages and cookies that cannot be
+// decoded instead of failing. Each page is located using the page size table
+// and each cookie using its offset, so a corrupted record does not prevent
+// the decoder from reading the records that follow. The errors found while
+// decoding the file are available through Errors. It is the same as setting
+// DecoderOptions.RecoverCorrupted.
+func (b *BinaryCookies) RecoverCorrupted() {
+	b.opts.RecoverCorrupted = true
+}
+
+// Errors returns the errors skipped by Decode after calling RecoverCorrupted.
+func (b *BinaryCookies) Errors() []*DecodeError {
+	return b.errors
+}
+
+// Checksum returns the checksum stored in the file.
+func (b *BinaryCookies) Checksum() uint32 {
+	return b.checksum
+}
+
+// ChecksumValid reports whether the checksum stored in the file matches the
+// checksum computed from the bytes of every page that was decoded.
+func (b *BinaryCookies) ChecksumValid() bool {
+	return b.checksum == b.computed
+}
+
+// Trailer returns the bytes found after the checksum, if any. These bytes are
+// usually a length-prefixed binary property list, and can be passed to the
+// Encoder to produce a byte-identical copy of the original file.
+func (b *BinaryCookies) Trailer() []byte {
+	return b.trailer
+}
+
 // New returns an instance of the Binary Cookies class.
 func New(reader io.Reader) *BinaryCookies {
 	return &BinaryCookies{
-		file:  reader,
-		page:  []uint32{},
-		pages: []Page{},
+		file:        reader,
+		page:        []uint32{},
+		pages:       []Page{},
+		pageIndex:   -1,
+		cookieIndex: -1,
 	}
 }
@@ -0,0 +1,32 @@
+package binarycookies
+
+import "bytes"
+
+// NewDecoderBytes returns an instance of the Binary Cookies class that decodes
+// the file in data using the given options, or the default options if nil.
+//
+// The decoder does not copy the input, the cookie strings, the unknown fields
+// and the trailer alias data instead, which saves one allocation per field
+// when scanning many files. The caller must not modify data while the cookies
+// are in use. This includes the pages decoded with RecoverCorrupted, only the
+// orphaned cookies found with RecoverSlack are copied.
+func NewDecoderBytes(data []byte, opts *DecoderOptions) *BinaryCookies {
+	b := NewDecoder(bytes.NewReader(data), opts)
+
+	// NOTES(cixtor): files larger than the limit go through the io.Reader so
+	// the error is reported at the same position as with NewDecoder.
+	if opts != nil && checkLimit(int64(len(data)), opts.MaxFileSize) != nil {
+		return b
+	}
+
+	b.input = data
+	b.zeroCopy = true
+
+	return b
+}
+
+// DecodeBytes decodes the file in data and returns all cookies. The cookie
+// strings alias data, see NewDecoderBytes.
+func DecodeBytes(data []byte) ([]Page, error) {
+	return NewDecoderBytes(data, nil).Decode()
+}
@@ -0,0 +1,167 @@
+package binarycookies
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDecodeBytes(t *testing.T) {
+	for _, data := range [][]byte{_test1, _test2} {
+		cook := New(bytes.NewReader(data))
+		expected, err := cook.Decode()
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		dec := NewDecoderBytes(data, &DecoderOptions{DisallowInvalidChecksum: true})
+		pages, err := dec.Decode()
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(pages, expected) {
+			t.Fatalf("incorrect pages\n- %v\n+ %v", expected, pages)
+		}
+
+		if !bytes.Equal(dec.Trailer(), cook.Trailer()) {
+			t.Fatalf("incorrect trailer\n- %#v\n+ %#v", cook.Trailer(), dec.Trailer())
+		}
+
+		var buf bytes.Buffer
+
+		enc := NewEncoder(&buf)
+		enc.SetTrailer(dec.Trailer())
+
+		if err := enc.Encode(pages); err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(buf.Bytes(), data) {
+			t.Fatalf("incorrect encoding\n- %#v\n+ %#v", data, buf.Bytes())
+		}
+	}
+}
+
+func TestDecodeBytesAlias(t *testing.T) {
+	data := append([]byte{}, _test1...)
+
+	pages, err := DecodeBytes(data)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// NOTES(cixtor): the domain of the first cookie in page #1 starts at 56
+	// bytes from the beginning of the cookie, which starts at offset 92.
+	data[92+56] = 'U'
+
+	if domain := string(pages[1].Cookies[0].Domain); domain != "Urlecho.appspot.com" {
+		t.Fatalf("cookie domain should alias the input\n- %s\n+ %s", "Urlecho.appspot.com", domain)
+	}
+
+	name := append(pages[1].Cookies[0].Domain, 'x')
+
+	if data[92+56+len(name)-1] == 'x' {
+		t.Fatal("appending to a cookie string should not modify the input")
+	}
+}
+
+func TestDecodeBytesAliasRecoverCorrupted(t *testing.T) {
+	data := append([]byte{}, _test1...)
+
+	pages, err := NewDecoderBytes(data, &DecoderOptions{RecoverCorrupted: true}).Decode()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data[92+56] = 'X'
+
+	if domain := string(pages[1].Cookies[0].Domain); domain != "Xrlecho.appspot.com" {
+		t.Fatalf("recovered cookie domain should alias the input\n- %s\n+ %s", "Xrlecho.appspot.com", domain)
+	}
+}
+
+func TestDecodeBytesErrors(t *testing.T) {
+	var e *DecodeError
+
+	_, err := DecodeBytes(_test2[:301])
+
+	if !errors.As(err, &e) || !errors.Is(err, ErrTruncated) {
+		t.Fatalf("truncated file should return %s, got %v", ErrTruncated, err)
+	}
+
+	if e.Field != "domain" || e.Page != 0 || e.Cookie != 2 || e.Offset != 301 {
+		t.Fatalf("incorrect error position\n- \"domain\" page #0 cookie #2 at offset 301\n+ %s", e)
+	}
+
+	if _, err := DecodeBytes(_test3); !errors.Is(err, ErrBadSignature) {
+		t.Fatalf("invalid binary cookies file should return %s, got %v", ErrBadSignature, err)
+	}
+
+	opts := DecoderOptions{MaxFileSize: int64(len(_test1) - 1)}
+
+	if _, err := NewDecoderBytes(_test1, &opts).Decode(); !errors.Is(err, ErrLimitExceeded) {
+		t.Fatalf("file larger than the limit should return %s, got %v", ErrLimitExceeded, err)
+	}
+}
+
+func TestOpenMapped(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "Cookies.binarycookies")
+
+	if err := os.WriteFile(name, _test1, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := OpenMapped(name)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer file.Close()
+
+	if !bytes.Equal(file.Bytes(), _test1) {
+		t.Fatal("incorrect mapped file content")
+	}
+
+	pages, err := NewDecoderBytes(file.Bytes(), nil).Decode()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(pages) != 11 {
+		t.Fatalf("incorrect number of pages\n- %d\n+ %d", 11, len(pages))
+	}
+
+	if err := file.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func BenchmarkDecodeReader(b *testing.B) {
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := New(bytes.NewReader(_test1)).Decode(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecodeBytes(b *testing.B) {
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := DecodeBytes(_test1); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
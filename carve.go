@@ -0,0 +1,266 @@
+package binarycookies
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+)
+
+// carveChunk is the number of bytes read from the stream at once.
+const carveChunk = 1 << 20
+
+// carveOverlap is the number of bytes kept from one chunk to the next, so a
+// record crossing the boundary between two chunks is not missed. It is large
+// enough for the biggest valid cookie, and for page headers and page size
+// tables with a few thousand entries.
+const carveOverlap = 1 << 14
+
+// CarvedCookie is a cookie found by the Carver. The Start and End fields of
+// the cookie are the position of the record in the stream.
+type CarvedCookie struct {
+	Cookie
+
+	// Page is the position of the page tag of the page that references the
+	// cookie, or -1 if the cookie was found on its own.
+	Page int64
+
+	// File is the position of the "cook" signature of the file that contains
+	// the page, or -1 if the page was found on its own.
+	File int64
+}
+
+// carveRef is the page and the file expected to contain a cookie.
+type carveRef struct {
+	page int64
+	file int64
+}
+
+// Carver finds cookie records in an arbitrary stream of bytes, for example, a
+// raw disk image or a memory dump containing deleted or fragmented binary
+// cookies files.
+//
+// Every position in the stream is checked for a plausible cookie header, that
+// is, a header with the cookie end marker, the first string right after the
+// header, and a valid size, offsets and timestamps. The strings must be null
+// terminated and the domain, name and path cannot be empty. File signatures
+// and page tags are used to tell which page and file a cookie belongs to.
+type Carver struct {
+	r      io.Reader
+	buf    []byte
+	base   int64
+	pos    int
+	eof    bool
+	err    error
+	cookie CarvedCookie
+	refs   map[int64]carveRef
+	files  map[int64]int64
+}
+
+// NewCarver returns a Carver that reads from r.
+func NewCarver(r io.Reader) *Carver {
+	return &Carver{
+		r:     r,
+		refs:  map[int64]carveRef{},
+		files: map[int64]int64{},
+	}
+}
+
+// Next finds the next cookie in the stream, which is then available through
+// Cookie, and reports whether there was one. It returns false at the end of
+// the stream or when an error occurs, in which case Err returns the error.
+func (c *Carver) Next() bool {
+	for {
+		limit := len(c.buf) - carveOverlap
+
+		if c.eof {
+			limit = len(c.buf)
+		}
+
+		for c.pos < limit {
+			p := c.pos
+			c.pos++
+
+			c.carveFile(p)
+			c.carvePage(p)
+
+			if size, ok := c.carveCookie(p); ok {
+				c.pos = p + int(size)
+				return true
+			}
+		}
+
+		if c.eof || c.err != nil {
+			return false
+		}
+
+		c.fill()
+	}
+}
+
+// Cookie returns the most recent cookie found by Next.
+func (c *Carver) Cookie() CarvedCookie {
+	return c.cookie
+}
+
+// Err returns the first error found while reading the stream, if any.
+func (c *Carver) Err() error {
+	return c.err
+}
+
+// fill drops the bytes already checked and reads the next chunk.
+func (c *Carver) fill() {
+	c.base += int64(c.pos)
+	c.buf = append(c.buf[:0], c.buf[c.pos:]...)
+	c.pos = 0
+
+	// NOTES(cixtor): references to positions already checked can no longer
+	// match, they are removed to keep the memory bounded.
+	for pos := range c.refs {
+		if pos < c.base {
+			delete(c.refs, pos)
+		}
+	}
+
+	for pos := range c.files {
+		if pos < c.base {
+			delete(c.files, pos)
+		}
+	}
+
+	n := len(c.buf)
+	c.buf = append(c.buf, make([]byte, carveChunk)...)
+	m, err := io.ReadFull(c.r, c.buf[n:])
+	c.buf = c.buf[:n+m]
+
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		c.eof = true
+	} else if err != nil {
+		c.err = err
+	}
+}
+
+// carveFile checks for a file signature at p, and records the position of
+// every page according to the page size table.
+func (c *Carver) carveFile(p int) {
+	data := c.buf[p:]
+
+	if len(data) < 8 || !bytes.Equal(data[:4], magic) {
+		return
+	}
+
+	count := int64(binary.BigEndian.Uint32(data[4:]))
+
+	if count == 0 || 8+4*count > int64(len(data)) {
+		return
+	}
+
+	file := c.base + int64(p)
+	pos := file + 8 + 4*count
+
+	for i := int64(0); i < count; i++ {
+		c.files[pos] = file
+		pos += int64(binary.BigEndian.Uint32(data[8+4*i:]))
+	}
+}
+
+// carvePage checks for a page header at p, and records the position of every
+// cookie according to the cookie offsets.
+func (c *Carver) carvePage(p int) {
+	data := c.buf[p:]
+
+	if len(data) < 12 || !bytes.Equal(data[:4], pageTag) {
+		return
+	}
+
+	count := int64(binary.LittleEndian.Uint32(data[4:]))
+	header := 12 + 4*count
+
+	if count == 0 || header > int64(len(data)) || binary.LittleEndian.Uint32(data[header-4:]) != 0 {
+		return
+	}
+
+	offsets := make([]int64, count)
+
+	for i := range offsets {
+		offsets[i] = int64(binary.LittleEndian.Uint32(data[8+4*i:]))
+
+		if offsets[i] < header || (i > 0 && offsets[i] <= offsets[i-1]) {
+			return
+		}
+	}
+
+	page := c.base + int64(p)
+	file, ok := c.files[page]
+
+	if !ok {
+		file = -1
+	}
+
+	delete(c.files, page)
+
+	for _, offset := range offsets {
+		c.refs[page+offset] = carveRef{page: page, file: file}
+	}
+}
+
+// carveCookie checks for a cookie at p and returns its size if it is valid.
+func (c *Carver) carveCookie(p int) (uint32, bool) {
+	pos := c.base + int64(p)
+	cookie, ok := carveCookieAt(c.buf[p:], pos, DecoderOptions{})
+
+	if !ok {
+		return 0, false
+	}
+
+	ref, ok := c.refs[pos]
+
+	if !ok {
+		ref = carveRef{page: -1, file: -1}
+	}
+
+	delete(c.refs, pos)
+
+	c.cookie = CarvedCookie{Cookie: cookie, Page: ref.page, File: ref.file}
+
+	return cookie.Size, true
+}
+
+// carveCookieAt decodes the cookie at the beginning of data, which corresponds
+// to the given position in the stream, and reports whether it looks like a
+// cookie written by WebKit rather than random bytes.
+func carveCookieAt(data []byte, pos int64, opts DecoderOptions) (Cookie, bool) {
+	if len(data) < cookieHeaderSize || !bytes.Equal(data[36:40], []byte{0x0, 0x0, 0x0, 0x0}) {
+		return Cookie{}, false
+	}
+
+	size := binary.LittleEndian.Uint32(data)
+	first := binary.LittleEndian.Uint32(data[16:])
+
+	if comment := binary.LittleEndian.Uint32(data[32:]); comment != 0 {
+		first = comment
+	}
+
+	if first != cookieHeaderSize || size <= cookieHeaderSize || int64(size) > int64(len(data)) {
+		return Cookie{}, false
+	}
+
+	cookie, err := newDecoderAt(bytes.NewReader(data), 0, int64(size), pos, -1, -1, opts).readPageCookie()
+
+	if err != nil {
+		return Cookie{}, false
+	}
+
+	for _, s := range cookieStrings(&cookie) {
+		if data[s.end-1] != 0x0 {
+			return Cookie{}, false
+		}
+	}
+
+	for _, s := range [][]byte{cookie.Domain, cookie.Name, cookie.Path} {
+		if len(s) == 0 || bytes.IndexByte(s, 0x0) >= 0 {
+			return Cookie{}, false
+		}
+	}
+
+	return cookie, true
+}
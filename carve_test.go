@@ -0,0 +1,112 @@
+package binarycookies
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/rand"
+	"testing"
+	"testing/iotest"
+)
+
+func carveAll(t *testing.T, r io.Reader) []CarvedCookie {
+	var cookies []CarvedCookie
+
+	c := NewCarver(r)
+
+	for c.Next() {
+		cookies = append(cookies, c.Cookie())
+	}
+
+	if err := c.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	return cookies
+}
+
+func TestCarve(t *testing.T) {
+	var image []byte
+
+	garbage := func(n int) {
+		data := make([]byte, n)
+		rand.New(rand.NewSource(int64(n))).Read(data)
+		image = append(image, data...)
+	}
+
+	// NOTES(cixtor): the first file crosses the boundary between the first
+	// two chunks, and the first cookie in page #1 of _test1 appears once more
+	// on its own, as if the rest of the file was overwritten.
+	garbage(carveChunk - 300)
+	test1 := int64(len(image))
+	image = append(image, _test1...)
+	garbage(5001)
+	test2 := int64(len(image))
+	image = append(image, _test2...)
+	garbage(777)
+	lone := int64(len(image))
+	image = append(image, _test1[92:185]...)
+	garbage(123)
+
+	var expected []CarvedCookie
+
+	for _, file := range []struct {
+		data []byte
+		pos  int64
+	}{{_test1, test1}, {_test2, test2}} {
+		pages, err := New(bytes.NewReader(file.data)).Decode()
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, page := range pages {
+			for _, cookie := range page.Cookies {
+				cookie.Start += file.pos
+				cookie.End += file.pos
+				expected = append(expected, CarvedCookie{Cookie: cookie, Page: file.pos + page.Start, File: file.pos})
+			}
+		}
+	}
+
+	cookie := expected[0].Cookie
+	cookie.Start = lone
+	cookie.End = lone + 93
+	expected = append(expected, CarvedCookie{Cookie: cookie, Page: -1, File: -1})
+
+	cookies := carveAll(t, bytes.NewReader(image))
+
+	if len(cookies) != len(expected) {
+		t.Fatalf("incorrect number of carved cookies\n- %d\n+ %d", len(expected), len(cookies))
+	}
+
+	for i := range expected {
+		if cookies[i].String() != expected[i].String() || cookies[i].Start != expected[i].Start || cookies[i].End != expected[i].End {
+			t.Fatalf("incorrect carved cookie #%d\n- %d %s\n+ %d %s", i, expected[i].Start, expected[i], cookies[i].Start, cookies[i])
+		}
+
+		if cookies[i].Page != expected[i].Page || cookies[i].File != expected[i].File {
+			t.Fatalf("incorrect carved cookie #%d source\n- page %d file %d\n+ page %d file %d", i, expected[i].Page, expected[i].File, cookies[i].Page, cookies[i].File)
+		}
+	}
+}
+
+func TestCarveShortReads(t *testing.T) {
+	expected := carveAll(t, bytes.NewReader(_test1))
+	cookies := carveAll(t, iotest.OneByteReader(bytes.NewReader(_test1)))
+
+	if len(cookies) != len(expected) || len(cookies) == 0 {
+		t.Fatalf("incorrect number of carved cookies\n- %d\n+ %d", len(expected), len(cookies))
+	}
+}
+
+func TestCarveReadError(t *testing.T) {
+	c := NewCarver(iotest.TimeoutReader(bytes.NewReader(_test1)))
+
+	for c.Next() {
+	}
+
+	if err := c.Err(); !errors.Is(err, iotest.ErrTimeout) {
+		t.Fatalf("read errors should be returned\n- %s\n+ %v", iotest.ErrTimeout, err)
+	}
+}
@@ -3,3 +3,7 @@ module github.com/cixtor/binarycookies/cmd/binarycookies
 go 1.14
 
 require github.com/cixtor/binarycookies v1.3.0
+
+// NOTES(cixtor): the command uses APIs that are not tagged yet, remove this
+// line and update the version above and go.sum when they are released.
+replace github.com/cixtor/binarycookies => ../../
@@ -14,16 +14,25 @@ var filename string
 var flagJSON bool
 var netscape bool
 var filter string
+var carve bool
+var slack bool
+var stat bool
+var importNetscape bool
 
 func main() {
 	flag.Usage = func() {
-		fmt.Println("Usage: binarycookies [-json|-netscape] [-filter regexp] [/path/to/Cookies.binarycookies]")
+		fmt.Println("Usage: binarycookies [-json|-netscape] [-filter regexp] [-carve|-slack|-stat] [/path/to/Cookies.binarycookies]")
+		fmt.Println("       binarycookies -import [/path/to/cookies.txt] > [/path/to/Cookies.binarycookies]")
 		flag.PrintDefaults()
 	}
 
 	flag.BoolVar(&flagJSON, "json", false, "print the output in JSON format")
 	flag.BoolVar(&netscape, "netscape", false, "use the Netscape cookie format")
 	flag.StringVar(&filter, "filter", "", "filter results by regexp on domain")
+	flag.BoolVar(&carve, "carve", false, "recover cookies from a disk image or memory dump")
+	flag.BoolVar(&slack, "slack", false, "recover old cookies from the unused bytes of every page")
+	flag.BoolVar(&stat, "stat", false, "print the number of pages and cookies without decoding them")
+	flag.BoolVar(&importNetscape, "import", false, "convert a Netscape cookie file into binary cookies")
 
 	flag.Parse()
 
@@ -37,6 +46,16 @@ func main() {
 		return
 	}
 
+	if (carve || stat) && netscape {
+		fmt.Println("-carve and -stat cannot be used with -netscape")
+		return
+	}
+
+	if importNetscape && (carve || slack || stat || flagJSON || netscape || filter != "") {
+		fmt.Println("-import cannot be used with -carve, -slack, -stat, -json, -netscape or -filter")
+		return
+	}
+
 	var re *regexp.Regexp
 	if len(filter) > 0 {
 		var err error
@@ -55,48 +74,64 @@ func main() {
 
 	defer file.Close()
 
-	cook := binarycookies.New(file)
+	if carve {
+		carveCookies(file, re)
+		return
+	}
 
-	pages, err := cook.Decode()
+	if stat {
+		statCookies(file)
+		return
+	}
 
-	if err != nil {
-		fmt.Println(err)
+	if importNetscape {
+		importCookies(file)
 		return
 	}
 
+	cook := binarycookies.NewDecoder(file, &binarycookies.DecoderOptions{RecoverSlack: slack})
+
+	enc := binarycookies.NewNetscapeEncoder(os.Stdout)
+
 	if netscape {
-		fmt.Println("# Netscape HTTP Cookie File")
+		if err := enc.WriteHeader(); err != nil {
+			fmt.Println(err)
+			return
+		}
 	}
 
 	var allCookies []binarycookies.Cookie
 
-	for _, page := range pages {
-		for _, cookie := range page.Cookies {
-			if re != nil && !re.Match(cookie.Domain) {
-				continue
-			}
+	for cook.Next() {
+		cookie := cook.Cookie()
 
-			if flagJSON {
-				allCookies = append(allCookies, cookie)
-				continue
-			}
+		if re != nil && !re.Match(cookie.Domain) {
+			continue
+		}
+
+		if flagJSON {
+			allCookies = append(allCookies, cookie)
+			continue
+		}
 
-			if netscape {
-				fmt.Printf(
-					"%s\t%s\t%s\t%s\t%d\t%s\t%s\n",
-					cookie.Domain,
-					boolField(cookie.Flags&0x40 == 0),
-					cookie.Path,
-					boolField(cookie.Flags&4 != 0),
-					cookie.Expires.Unix(),
-					cookie.Name,
-					cookie.Value,
-				)
-				continue
+		if netscape {
+			if err := enc.WriteCookie(cookie); err != nil {
+				fmt.Println(err)
+				return
 			}
+			continue
+		}
 
-			fmt.Println(cookie.String())
+		if cookie.Orphaned {
+			fmt.Print("(orphaned) ")
 		}
+
+		fmt.Println(cookie.String())
+	}
+
+	if err := cook.Err(); err != nil {
+		fmt.Println(err)
+		return
 	}
 
 	if flagJSON {
@@ -109,6 +144,83 @@ func main() {
 	}
 }
 
+// carveCookies prints every cookie found in the file, with the position of the
+// cookie, the page and the file it belongs to, or -1 if they are unknown.
+func carveCookies(file *os.File, re *regexp.Regexp) {
+	var allCookies []binarycookies.CarvedCookie
+
+	carver := binarycookies.NewCarver(file)
+
+	for carver.Next() {
+		cookie := carver.Cookie()
+
+		if re != nil && !re.Match(cookie.Domain) {
+			continue
+		}
+
+		if flagJSON {
+			allCookies = append(allCookies, cookie)
+			continue
+		}
+
+		fmt.Printf("%d\t%d\t%d\t%s\n", cookie.Start, cookie.Page, cookie.File, cookie.String())
+	}
+
+	if err := carver.Err(); err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	if flagJSON {
+		out, err := json.Marshal(allCookies)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		fmt.Printf("%s\n", out)
+	}
+}
+
+// statCookies prints the number of pages and cookies in the file, its size and
+// whether it has a trailer, followed by the file name.
+func statCookies(file *os.File) {
+	stats, err := binarycookies.Stat(file)
+
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	if flagJSON {
+		out, err := json.Marshal(stats)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		fmt.Printf("%s\n", out)
+		return
+	}
+
+	fmt.Printf("%d\t%d\t%d\t%s\t%s\n", stats.Pages, stats.Cookies, stats.Size, boolField(stats.Trailer), filename)
+}
+
+// importCookies writes the cookies in a Netscape cookie file as binary cookies
+// into the standard output. Errors go to the standard error instead, so they
+// do not end up in the output file.
+func importCookies(file *os.File) {
+	pages, err := binarycookies.ReadNetscape(file)
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if err := binarycookies.NewEncoder(os.Stdout).Encode(pages); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
 func boolField(b bool) string {
 	if b {
 		return "TRUE"
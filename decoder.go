@@ -3,12 +3,19 @@ package binarycookies
 import (
 	"bytes"
 	"encoding/binary"
+	"errors"
 	"fmt"
+	"io"
 	"math"
 	"time"
 )
 
 // Decode reads the entire file, validates and returns all cookies.
+//
+// A cookie with a timestamp that is not a number, is infinite, or is outside
+// the years 1 to 9999 fails the entire file with ErrBadTime. Older versions
+// returned these cookies with a meaningless date instead. Set Lenient to keep
+// them with a zero time, or RecoverCorrupted to skip them.
 func (b *BinaryCookies) Decode() ([]Page, error) {
 	if err := b.readSignature(); err != nil {
 		return nil, err
@@ -22,20 +29,34 @@ func (b *BinaryCookies) Decode() ([]Page, error) {
 		return nil, err
 	}
 
-	for i := 0; i < int(b.size); i++ {
-		if err := b.readOnePage(); err != nil {
+	if b.opts.Workers > 1 {
+		if err := b.readPagesParallel(b.opts.Workers); err != nil {
 			return nil, err
 		}
+	} else {
+		for i := 0; i < int(b.size); i++ {
+			if err := b.readOnePage(i); err != nil {
+				return nil, err
+			}
+		}
 	}
 
-	if err := b.readChecksum(); err != nil {
+	if err := b.readFooterAndTrailer(); err != nil {
 		return nil, err
 	}
 
-	// NOTES(cixtor): optional extra bytes may exist after this point, these
-	// bytes usually represent a Binary Property List (bplist00) and contain
-	// a dictionary with additional information, for example, the cookie accept
-	// policy for all tasks within sessions based on the website configuration.
+	return b.pages, nil
+}
+
+// readFooterAndTrailer reads the checksum, the footer and the optional binary
+// property list after the last page. In recovery mode the errors are recorded
+// and the function always succeeds.
+func (b *BinaryCookies) readFooterAndTrailer() error {
+	// NOTES(cixtor): the checksum and the footer follow the last page, then
+	// optional extra bytes may exist, these bytes usually represent a Binary
+	// Property List (bplist00) and contain a dictionary with additional data,
+	// for example, the cookie accept policy for all tasks within sessions
+	// based on the website configuration.
 	//
 	// Example:
 	//
@@ -53,21 +74,162 @@ func (b *BinaryCookies) Decode() ([]Page, error) {
 	//   "NSHTTPCookieAcceptPolicy" => 2
 	// }
 
-	return b.pages, nil
+	functions := []func() error{
+		b.readChecksum,
+		b.readFooter,
+		b.readTrailer,
+		b.readPolicy,
+	}
+
+	for _, fun := range functions {
+		if err := fun(); err != nil {
+			if !b.opts.RecoverCorrupted {
+				return err
+			}
+
+			b.fail(err)
+		}
+	}
+
+	return nil
+}
+
+// read reads exactly n bytes from the file and keeps track of the total number
+// of bytes consumed so far, which is used to locate pages and cookies.
+//
+// A single call to Read is allowed to return less bytes than requested, which
+// is common with pipes, network connections and compressed streams, so the
+// function reads until the buffer is full. The error is io.EOF only if no
+// bytes were read, and io.ErrUnexpectedEOF if the file ends halfway.
+func (b *BinaryCookies) read(n int) ([]byte, error) {
+	var err error
+	var data []byte
+
+	if b.zeroCopy {
+		data, err = b.slice(int64(n))
+	} else {
+		data = make([]byte, n)
+		n, err = io.ReadFull(b.file, data)
+		data = data[:n]
+	}
+
+	b.addChecksum(data)
+	b.offset += int64(len(data))
+
+	return data, err
+}
+
+// slice returns the next n bytes of the input without copying them. If there
+// are less than n bytes left, the remaining bytes are returned with an error,
+// in the same way as io.ReadFull does.
+func (b *BinaryCookies) slice(n int64) ([]byte, error) {
+	if n > 0 && len(b.input) == 0 {
+		return nil, io.EOF
+	}
+
+	if n > int64(len(b.input)) {
+		data := b.input
+		b.input = nil
+		return data, io.ErrUnexpectedEOF
+	}
+
+	// NOTES(cixtor): the capacity is limited to the length of the field so
+	// appending to a cookie string does not overwrite the rest of the input.
+	data := b.input[:n:n]
+	b.input = b.input[n:]
+
+	return data, nil
+}
+
+// addChecksum adds every fourth byte of a page, counting from the page tag, to
+// the checksum of the file. Bytes read outside the pages are ignored.
+func (b *BinaryCookies) addChecksum(data []byte) {
+	if !b.paging {
+		return
+	}
+
+	for i := (4 - (b.offset-b.pageStart)%4) % 4; i < int64(len(data)); i += 4 {
+		b.computed += uint32(data[i])
+	}
+}
+
+// readField reads and returns the next n bytes from the file. If the bytes
+// cannot be read, the error is annotated with the name of the field and its
+// position. When decoding a byte slice, the bytes alias the input.
+func (b *BinaryCookies) readField(field string, n int) ([]byte, error) {
+	offset := b.offset
+	data, err := b.read(n)
+
+	if err != nil {
+		return nil, b.errorAt(field, offset, err)
+	}
+
+	return data, nil
+}
+
+// errorAt returns a DecodeError for the field starting at the given offset in
+// the page and cookie being decoded. A premature end of file is reported as
+// ErrTruncated.
+func (b *BinaryCookies) errorAt(field string, offset int64, err error) error {
+	if err == io.EOF {
+		err = ErrTruncated
+	}
+
+	return &DecodeError{
+		Field:  field,
+		Page:   b.pageIndex,
+		Cookie: b.cookieIndex,
+		Offset: offset,
+		Err:    err,
+	}
+}
+
+// readSlack reads and returns the next n bytes in the file. These bytes are
+// not part of any page or cookie but are kept to re-encode the file exactly
+// as it was found.
+func (b *BinaryCookies) readSlack(field string, n int64) ([]byte, error) {
+	var buf bytes.Buffer
+
+	offset := b.offset
+
+	if b.zeroCopy {
+		data, err := b.slice(n)
+
+		b.addChecksum(data)
+		b.offset += int64(len(data))
+
+		if err != nil {
+			return data, b.errorAt(field, offset, err)
+		}
+
+		return data, nil
+	}
+
+	m, err := io.CopyN(&buf, b.file, n)
+
+	b.addChecksum(buf.Bytes())
+	b.offset += m
+
+	if err != nil {
+		return buf.Bytes(), b.errorAt(field, offset, err)
+	}
+
+	return buf.Bytes(), nil
 }
 
 // readSignature reads a number of bytes that are supposed to represent the
 // magic number of valid binary cookies. If the file format is different then
 // the function returns an error with some information.
 func (b *BinaryCookies) readSignature() error {
-	data := make([]byte, 4)
+	offset := b.offset
+	data, err := b.readField("signature", 4)
 
-	if n, err := b.file.Read(data); err != nil {
-		return fmt.Errorf("readSignature %q; %w", data[:n], err)
+	if err != nil {
+		return err
 	}
 
 	if !bytes.Equal(data, magic) {
-		return fmt.Errorf("readSignature invalid signature %q", data)
+		return b.errorAt("signature", offset, ErrBadSignature)
 	}
 
 	return nil
@@ -75,99 +237,173 @@ func (b *BinaryCookies) readSignature() error {
 
 // readPageSize reads an integer representing the number of pages in the file.
 func (b *BinaryCookies) readPageSize() error {
-	data := make([]byte, 4)
+	offset := b.offset
+	data, err := b.readField("page count", 4)
 
-	if n, err := b.file.Read(data); err != nil {
-		return fmt.Errorf("readPageSize from %q; %w", data[:n], err)
+	if err != nil {
+		return err
 	}
 
 	b.size = binary.BigEndian.Uint32(data)
 
+	if err := checkLimit(int64(b.size), int64(b.opts.MaxPages)); err != nil {
+		return b.errorAt("page count", offset, err)
+	}
+
 	return nil
 }
 
 // readAllPages reads the size for all pages in the file.
 func (b *BinaryCookies) readAllPages() error {
 	size := int(b.size)
-	data := make([]byte, 4)
+	total := int64(8 + 4*size)
 
 	for i := 0; i < size; i++ {
-		if n, err := b.file.Read(data); err != nil {
-			return fmt.Errorf("readAllPages %q; %w", data[:n], err)
+		data, err := b.readField("page size", 4)
+
+		if err != nil {
+			return err
 		}
 
-		b.page = append(b.page, binary.BigEndian.Uint32(data))
+		n := binary.BigEndian.Uint32(data)
+		total += int64(n)
+
+		// NOTES(cixtor): the pages alone cannot be larger than the file, so
+		// the file size limit is checked before reading any of them.
+		if err := checkLimit(total, b.opts.MaxFileSize); err != nil {
+			return b.errorAt("page size", b.offset-4, err)
+		}
+
+		b.page = append(b.page, n)
 	}
 
 	return nil
 }
 
+// fail records an error skipped while recovering a corrupted file.
+func (b *BinaryCookies) fail(err error) {
+	var e *DecodeError
+
+	if !errors.As(err, &e) {
+		e = b.errorAt("", b.offset, err).(*DecodeError)
+	}
+
+	b.errors = append(b.errors, e)
+}
+
 // readOnePage reads one single page in the file.
-func (b *BinaryCookies) readOnePage() error {
-	data := make([]byte, 4)
+func (b *BinaryCookies) readOnePage(index int) error {
+	b.paging = true
+	b.pageStart = b.offset
+	b.pageIndex = index
+
+	defer func() {
+		b.paging = false
+		b.pageIndex = -1
+	}()
+
+	page, err := b.readPage(index)
 
-	if n, err := b.file.Read(data); err != nil {
-		return fmt.Errorf("readOnePage page tag %q; %w", data[:n], err)
+	if err != nil {
+		return err
 	}
 
-	if !bytes.Equal(data, []byte{0x0, 0x0, 0x1, 0x0}) {
-		return fmt.Errorf("readOnePage invalid page tag %q", data)
+	b.pages = append(b.pages, page)
+
+	return nil
+}
+
+// readPage reads the entire page into memory, according to the size in the
+// page size table, and then decodes every cookie using its offset, the same
+// way the PageReader does. In recovery mode the errors are recorded and the
+// page contains only the cookies that could be decoded.
+func (b *BinaryCookies) readPage(index int) (Page, error) {
+	var fail func(error)
+
+	if b.opts.RecoverCorrupted {
+		fail = b.fail
 	}
 
-	if n, err := b.file.Read(data); err != nil {
-		return fmt.Errorf("readOnePage number of cookies %q; %w", data[:n], err)
+	start := b.offset
+	data, readErr := b.readSlack("page", int64(b.page[index]))
+
+	if readErr != nil {
+		if fail != nil {
+			fail(readErr)
+		} else if !errors.Is(readErr, ErrTruncated) {
+			return Page{}, readErr
+		}
 	}
 
-	length := binary.LittleEndian.Uint32(data)
-	offsets := make([]uint32, int(length))
+	// NOTES(cixtor): if the file is truncated, the cookies are decoded anyway
+	// to report the field that could not be read instead of the entire page.
+	page, err := readPageAt(byteSection(data), 0, int64(len(data)), start, index, b.opts, fail)
 
-	for i := 0; i < int(length); i++ {
-		if n, err := b.file.Read(data); err != nil {
-			return fmt.Errorf("readOnePage cookie offset %q; %w", data[:n], err)
+	if err != nil {
+		if fail == nil {
+			return Page{}, err
 		}
 
-		offsets[i] = binary.LittleEndian.Uint32(data)
+		fail(err)
 	}
 
-	if n, err := b.file.Read(data); err != nil {
-		return fmt.Errorf("readOnePage page end %q; %w", data[:n], err)
+	if readErr != nil && fail == nil {
+		return Page{}, readErr
 	}
 
-	if !bytes.Equal(data, []byte{0x0, 0x0, 0x0, 0x0}) {
-		return fmt.Errorf("readOnePage invalid page end %q", data)
-	}
+	// NOTES(cixtor): the page may be shorter than the declared size if the
+	// file is truncated, the position is taken from the page size table.
+	page.Start = start
+	page.Size = b.page[index]
+	page.End = start + int64(page.Size)
 
-	cookies, err := b.readPageCookies(length)
+	return page, nil
+}
+
+// readPageHeader reads the page tag, the number of cookies in the page, the
+// offset of each cookie relative to the page tag and the page end marker.
+func (b *BinaryCookies) readPageHeader() (Page, error) {
+	offset := b.offset
+	data, err := b.readField("page tag", 4)
 
 	if err != nil {
-		return err
+		return Page{}, err
 	}
 
-	b.pages = append(b.pages, Page{
-		Length:  length,
-		Offsets: offsets,
-		Cookies: cookies,
-	})
+	if !bytes.Equal(data, pageTag) && !b.opts.Lenient {
+		return Page{}, b.errorAt("page tag", offset, ErrBadPageTag)
+	}
 
-	return nil
-}
+	if data, err = b.readField("cookie count", 4); err != nil {
+		return Page{}, err
+	}
 
-// readPageCookies reads and returns all cookies associated to a single page.
-func (b *BinaryCookies) readPageCookies(length uint32) ([]Cookie, error) {
-	var err error
-	var cookie Cookie
+	length := binary.LittleEndian.Uint32(data)
+	offsets := []uint32{}
 
-	cookies := make([]Cookie, length)
+	if err := checkLimit(int64(length), int64(b.opts.MaxCookiesPerPage)); err != nil {
+		return Page{}, b.errorAt("cookie count", b.offset-4, err)
+	}
 
-	for i := uint32(0); i < length; i++ {
-		if cookie, err = b.readPageCookie(); err != nil {
-			return []Cookie{}, err
+	// NOTES(cixtor): the number of cookies comes from the file and cannot be
+	// trusted to pre-allocate memory, the offsets grow as they are read.
+	for i := 0; i < int(length); i++ {
+		if data, err = b.readField("cookie offset", 4); err != nil {
+			return Page{}, err
 		}
 
-		cookies[i] = cookie
+		offsets = append(offsets, binary.LittleEndian.Uint32(data))
 	}
 
-	return cookies, nil
+	if data, err = b.readField("page end", 4); err != nil {
+		return Page{}, err
+	}
+
+	if !bytes.Equal(data, []byte{0x0, 0x0, 0x0, 0x0}) && !b.opts.Lenient {
+		return Page{}, b.errorAt("page end", b.offset-4, ErrBadPageEnd)
+	}
+
+	return Page{Length: length, Offsets: offsets}, nil
 }
 
 // readPageCookie reads and returns one cookie associated to a single page.
@@ -175,6 +411,8 @@ func (b *BinaryCookies) readPageCookie() (Cookie, error) {
 	var err error
 	var cookie Cookie
 
+	offset := b.offset
+
 	functions := []cookieHelperFunction{
 		b.readPageCookieSize,
 		b.readPageCookieUnknownOne,
@@ -188,6 +426,7 @@ func (b *BinaryCookies) readPageCookie() (Cookie, error) {
 		b.readPageCookieEndHeader,
 		b.readPageCookieExpires,
 		b.readPageCookieCreation,
+		b.checkCookieOffsets,
 		b.checkCookieOverallSize,
 		b.readPageCookieComment,
 		b.readPageCookieDomain,
@@ -198,13 +437,47 @@ func (b *BinaryCookies) readPageCookie() (Cookie, error) {
 
 	for _, fun := range functions {
 		if err = fun(&cookie); err != nil {
+			var e *DecodeError
+
+			if !errors.As(err, &e) {
+				err = b.errorAt("cookie", offset, err)
+			}
+
 			return Cookie{}, err
 		}
 	}
 
+	cookie.Start = offset
+	cookie.End = b.offset
+
 	return cookie, nil
 }
 
+// checkCookieOffsets validates that the cookie strings are stored in order and
+// that each one has at least one byte for the null terminator. The comment is
+// optional and is only validated if the offset is different than zero.
+func (b *BinaryCookies) checkCookieOffsets(cookie *Cookie) error {
+	offsets := []uint32{
+		cookie.DomainOffset,
+		cookie.NameOffset,
+		cookie.PathOffset,
+		cookie.ValueOffset,
+		cookie.Size,
+	}
+
+	if cookie.CommentOffset != 0 {
+		offsets = append([]uint32{cookie.CommentOffset}, offsets...)
+	}
+
+	for i := 1; i < len(offsets); i++ {
+		if offsets[i] <= offsets[i-1] {
+			return fmt.Errorf("string offsets %v; %w", offsets, ErrBadOffset)
+		}
+	}
+
+	return nil
+}
+
 // RFC 2965
 // HTTP State Management Mechanism
 // https://www.ietf.org/rfc/rfc2965.txt
@@ -236,23 +509,25 @@ func (b *BinaryCookies) checkCookieOverallSize(cookie *Cookie) error {
 	var total uint32
 
 	sizes := []uint32{
-		/* Cookie Domain  */ (cookie.domainOffset - cookie.commentOffset),
-		/* Cookie Name    */ (cookie.nameOffset - cookie.domainOffset),
-		/* Cookie Path    */ (cookie.pathOffset - cookie.nameOffset),
-		/* Cookie Value   */ (cookie.valueOffset - cookie.pathOffset),
-		/* Cookie Comment */ (cookie.Size - cookie.valueOffset),
+		/* Cookie Domain  */ (cookie.DomainOffset - cookie.CommentOffset),
+		/* Cookie Name    */ (cookie.NameOffset - cookie.DomainOffset),
+		/* Cookie Path    */ (cookie.PathOffset - cookie.NameOffset),
+		/* Cookie Value   */ (cookie.ValueOffset - cookie.PathOffset),
+		/* Cookie Comment */ (cookie.Size - cookie.ValueOffset),
 	}
 
+	limit := b.opts.maxCookieSize()
+
 	// Check if cookie component in case of uint overflow.
 	for i, n := range sizes {
-		if n > maxCookieSize {
-			return fmt.Errorf("maximum cookie size exceeded in component[%d] (%d > 4096)", i, n)
+		if n > limit {
+			return fmt.Errorf("maximum cookie size exceeded in component[%d] (%d > %d); %w", i, n, limit, ErrCookieTooLarge)
 		}
 
 		total += n
 	}
 
-	if total > maxCookieSize {
+	if total > limit {
 		// Cookie Limitations
 		//
 		// Most browsers support cookies of up to 4096 bytes. Because of this
@@ -262,7 +537,7 @@ func (b *BinaryCookies) checkCookieOverallSize(cookie *Cookie) error {
 		// database or other data store.
 		//
 		// http://msdn.microsoft.com/en-us/library/ms178194.aspx
-		return fmt.Errorf("maximum overall cookie size exceeded %d > 4096", total)
+		return fmt.Errorf("maximum overall cookie size exceeded %d > %d; %w", total, limit, ErrCookieTooLarge)
 	}
 
 	return nil
@@ -270,10 +545,10 @@ func (b *BinaryCookies) checkCookieOverallSize(cookie *Cookie) error {
 
 // readPageCookieSize reads and stores the cookie size.
 func (b *BinaryCookies) readPageCookieSize(cookie *Cookie) error {
-	data := make([]byte, 4)
+	data, err := b.readField("cookie size", 4)
 
-	if n, err := b.file.Read(data); err != nil {
-		return fmt.Errorf("readPageCookie size %q; %w", data[:n], err)
+	if err != nil {
+		return err
 	}
 
 	cookie.Size = binary.LittleEndian.Uint32(data)
@@ -283,14 +558,14 @@ func (b *BinaryCookies) readPageCookieSize(cookie *Cookie) error {
 
 // readPageCookieUnknownOne reads and stores one of the unknown cookie fields.
 func (b *BinaryCookies) readPageCookieUnknownOne(cookie *Cookie) error {
-	data := make([]byte, 4)
-
 	// NOTES(cixtor): unknown field that some people believe is related to the
 	// cookie flags but so far no relevant articles online have been able to
 	// confirm this claim. It may be possible to discover the purpose of these
 	// bytes with some reverse engineering work on modern browsers.
-	if n, err := b.file.Read(data); err != nil {
-		return fmt.Errorf("readPageCookie unknown one %q; %w", data[:n], err)
+	data, err := b.readField("unknown one", 4)
+
+	if err != nil {
+		return err
 	}
 
 	cookie.unknownOne = data
@@ -300,45 +575,37 @@ func (b *BinaryCookies) readPageCookieUnknownOne(cookie *Cookie) error {
 
 // readPageCookieFlags reads and stores the cookie flags.
 func (b *BinaryCookies) readPageCookieFlags(cookie *Cookie) error {
-	data := make([]byte, 4)
-
 	// NOTES(cixtor): cookie flags: secure, httpOnly, sameSite.
 	// - 0x0 = None
 	// - 0x1 = Secure
 	// - 0x4 = HttpOnly
 	// - 0x5 = Secure+HttpOnly
+	// Other bits are described by the Flags constants, and the bits that are
+	// not known are kept as they are to encode the cookie again.
 	// Ref: https://en.wikipedia.org/wiki/HTTP_cookie#Terminology
-	if n, err := b.file.Read(data); err != nil {
-		return fmt.Errorf("readPageCookie flags %q; %w", data[:n], err)
-	}
-
-	cookie.Flags = binary.LittleEndian.Uint32(data)
+	data, err := b.readField("flags", 4)
 
-	if cookie.Flags == 0x0 {
-		cookie.Secure = false
-		cookie.HttpOnly = false
-	} else if cookie.Flags == 0x1 {
-		cookie.Secure = true
-	} else if cookie.Flags == 0x4 {
-		cookie.HttpOnly = true
-	} else if cookie.Flags == 0x5 {
-		cookie.Secure = true
-		cookie.HttpOnly = true
+	if err != nil {
+		return err
 	}
 
+	cookie.Flags = Flags(binary.LittleEndian.Uint32(data))
+	cookie.Secure = cookie.Flags.Secure()
+	cookie.HttpOnly = cookie.Flags.HttpOnly()
+
 	return nil
 }
 
 // readPageCookieUnknownTwo reads and stores one of the unknown cookie fields.
 func (b *BinaryCookies) readPageCookieUnknownTwo(cookie *Cookie) error {
-	data := make([]byte, 4)
-
 	// NOTES(cixtor): unknown field that some people believe is related to the
 	// cookie flags but so far no relevant articles online have been able to
 	// confirm this claim. It may be possible to discover the purpose of these
 	// bytes with some reverse engineering work on modern browsers.
-	if n, err := b.file.Read(data); err != nil {
-		return fmt.Errorf("readPageCookie unknown two %q; %w", data[:n], err)
+	data, err := b.readField("unknown two", 4)
+
+	if err != nil {
+		return err
 	}
 
 	cookie.unknownTwo = data
@@ -348,79 +615,79 @@ func (b *BinaryCookies) readPageCookieUnknownTwo(cookie *Cookie) error {
 
 // readPageCookieDomainOffset reads and stores the cookie domain offset.
 func (b *BinaryCookies) readPageCookieDomainOffset(cookie *Cookie) error {
-	data := make([]byte, 4)
+	data, err := b.readField("domain offset", 4)
 
-	if n, err := b.file.Read(data); err != nil {
-		return fmt.Errorf("readPageCookie domain offset %q; %w", data[:n], err)
+	if err != nil {
+		return err
 	}
 
-	cookie.domainOffset = binary.LittleEndian.Uint32(data)
+	cookie.DomainOffset = binary.LittleEndian.Uint32(data)
 
 	return nil
 }
 
 // readPageCookieNameOffset reads and stores the cookie name offset.
 func (b *BinaryCookies) readPageCookieNameOffset(cookie *Cookie) error {
-	data := make([]byte, 4)
+	data, err := b.readField("name offset", 4)
 
-	if n, err := b.file.Read(data); err != nil {
-		return fmt.Errorf("readPageCookie name offset %q; %w", data[:n], err)
+	if err != nil {
+		return err
 	}
 
-	cookie.nameOffset = binary.LittleEndian.Uint32(data)
+	cookie.NameOffset = binary.LittleEndian.Uint32(data)
 
 	return nil
 }
 
 // readPageCookiePathOffset reads and stores the cookie path offset.
 func (b *BinaryCookies) readPageCookiePathOffset(cookie *Cookie) error {
-	data := make([]byte, 4)
+	data, err := b.readField("path offset", 4)
 
-	if n, err := b.file.Read(data); err != nil {
-		return fmt.Errorf("readPageCookie path offset %q; %w", data[:n], err)
+	if err != nil {
+		return err
 	}
 
-	cookie.pathOffset = binary.LittleEndian.Uint32(data)
+	cookie.PathOffset = binary.LittleEndian.Uint32(data)
 
 	return nil
 }
 
 // readPageCookieValueOffset reads and stores the cookie value offset.
 func (b *BinaryCookies) readPageCookieValueOffset(cookie *Cookie) error {
-	data := make([]byte, 4)
+	data, err := b.readField("value offset", 4)
 
-	if n, err := b.file.Read(data); err != nil {
-		return fmt.Errorf("readPageCookie value offset %q; %w", data[:n], err)
+	if err != nil {
+		return err
 	}
 
-	cookie.valueOffset = binary.LittleEndian.Uint32(data)
+	cookie.ValueOffset = binary.LittleEndian.Uint32(data)
 
 	return nil
 }
 
 // readPageCookieCommentOffset reads and stores the cookie comment offset.
 func (b *BinaryCookies) readPageCookieCommentOffset(cookie *Cookie) error {
-	data := make([]byte, 4)
+	data, err := b.readField("comment offset", 4)
 
-	if n, err := b.file.Read(data); err != nil {
-		return fmt.Errorf("readPageCookie comment offset %q; %w", data[:n], err)
+	if err != nil {
+		return err
 	}
 
-	cookie.commentOffset = binary.LittleEndian.Uint32(data)
+	cookie.CommentOffset = binary.LittleEndian.Uint32(data)
 
 	return nil
 }
 
 // readPageCookieEndHeader reads and validates the cookie end header.
 func (b *BinaryCookies) readPageCookieEndHeader(cookie *Cookie) error {
-	data := make([]byte, 4)
+	data, err := b.readField("end header", 4)
 
-	if n, err := b.file.Read(data); err != nil {
-		return fmt.Errorf("readPageCookie end header %q; %w", data[:n], err)
+	if err != nil {
+		return err
 	}
 
-	if !bytes.Equal(data, []byte{0x0, 0x0, 0x0, 0x0}) {
-		return fmt.Errorf("readPageCookie invalid end header %q", data)
+	if !bytes.Equal(data, []byte{0x0, 0x0, 0x0, 0x0}) && !b.opts.Lenient {
+		return b.errorAt("end header", b.offset-4, ErrBadEndHeader)
 	}
 
 	return nil
@@ -428,34 +695,58 @@ func (b *BinaryCookies) readPageCookieEndHeader(cookie *Cookie) error {
 
 // readPageCookieExpires reads and decodes the cookie expiration time.
 func (b *BinaryCookies) readPageCookieExpires(cookie *Cookie) error {
-	data := make([]byte, 8)
+	offset := b.offset
+	data, err := b.readField("expires", 8)
 
-	if n, err := b.file.Read(data); err != nil {
-		return fmt.Errorf("readPageCookie expiration time %q; %w", data[:n], err)
+	if err != nil {
+		return err
 	}
 
-	// NOTES(cixtor): convert Mac epoch time into a Unix timestamp.
 	number := math.Float64frombits(binary.LittleEndian.Uint64(data))
-	cookie.Expires = time.Unix(int64(number+timePadding), 0)
+
+	// NOTES(cixtor): in lenient mode an invalid timestamp is the zero time.
+	if cookie.Expires, err = decodeTime(number); err != nil && !b.opts.Lenient {
+		return b.errorAt("expires", offset, err)
+	}
 
 	return nil
 }
 
 // readPageCookieCreation reads and decodes the cookie creation time.
 func (b *BinaryCookies) readPageCookieCreation(cookie *Cookie) error {
-	data := make([]byte, 8)
+	offset := b.offset
+	data, err := b.readField("creation", 8)
 
-	if n, err := b.file.Read(data); err != nil {
-		return fmt.Errorf("readPageCookie creation time %q; %w", data[:n], err)
+	if err != nil {
+		return err
 	}
 
-	// NOTES(cixtor): convert Mac epoch time into a Unix timestamp.
 	number := math.Float64frombits(binary.LittleEndian.Uint64(data))
-	cookie.Creation = time.Unix(int64(number+timePadding), 0)
+
+	// NOTES(cixtor): in lenient mode an invalid timestamp is the zero time.
+	if cookie.Creation, err = decodeTime(number); err != nil && !b.opts.Lenient {
+		return b.errorAt("creation", offset, err)
+	}
 
 	return nil
 }
 
+// decodeTime converts Mac epoch time into a time.Time, keeping the fractional
+// seconds with nanosecond precision.
+func decodeTime(number float64) (time.Time, error) {
+	// NOTES(cixtor): NaN, infinity and dates outside the range of years that
+	// can be formatted with four digits only appear in corrupted files, and
+	// would otherwise wrap around when converted into an integer.
+	if math.IsNaN(number) || math.IsInf(number, 0) || number < minTime || number > maxTime {
+		return time.Time{}, fmt.Errorf("%v; %w", number, ErrBadTime)
+	}
+
+	sec, frac := math.Modf(number)
+	nsec := math.Round(frac * 1e9)
+
+	return time.Unix(int64(sec)+int64(timePadding), int64(nsec)), nil
+}
+
 // readPageCookieComment reads and stores the cookie comment field.
 //
 // Because cookies can contain private information about a user, the Cookie
@@ -469,86 +760,139 @@ func (b *BinaryCookies) readPageCookieCreation(cookie *Cookie) error {
 // 0x00000000 but it is important to distinguish between these values to read
 // the entire cookie data.
 func (b *BinaryCookies) readPageCookieComment(cookie *Cookie) error {
-	if cookie.commentOffset == 0 {
+	if cookie.CommentOffset == 0 {
 		return nil
 	}
 
-	data := make([]byte, cookie.domainOffset-cookie.commentOffset)
+	data, err := b.readField("comment", int(cookie.DomainOffset-cookie.CommentOffset))
 
-	if n, err := b.file.Read(data); err != nil {
-		return fmt.Errorf("readPageCookie comment text %q; %w", data[:n], err)
+	if err != nil {
+		return err
 	}
 
-	cookie.Comment = data
+	// NOTES(cixtor): fix null-terminated string.
+	cookie.Comment = data[0 : len(data)-1 : len(data)-1]
+	cookie.hasComment = true
 
 	return nil
 }
 
 // readPageCookieDomain reads and stores the cookie domain field.
 func (b *BinaryCookies) readPageCookieDomain(cookie *Cookie) error {
-	data := make([]byte, cookie.nameOffset-cookie.domainOffset)
+	data, err := b.readField("domain", int(cookie.NameOffset-cookie.DomainOffset))
 
-	if n, err := b.file.Read(data); err != nil {
-		return fmt.Errorf("readPageCookie domain text %q; %w", data[:n], err)
+	if err != nil {
+		return err
 	}
 
 	// NOTES(cixtor): fix null-terminated string.
-	cookie.Domain = data[0 : len(data)-1]
+	cookie.Domain = data[0 : len(data)-1 : len(data)-1]
 
 	return nil
 }
 
 // readPageCookieName reads and stores the cookie name field.
 func (b *BinaryCookies) readPageCookieName(cookie *Cookie) error {
-	data := make([]byte, cookie.pathOffset-cookie.nameOffset)
+	data, err := b.readField("name", int(cookie.PathOffset-cookie.NameOffset))
 
-	if n, err := b.file.Read(data); err != nil {
-		return fmt.Errorf("readPageCookie name text %q; %w", data[:n], err)
+	if err != nil {
+		return err
 	}
 
 	// NOTES(cixtor): fix null-terminated string.
-	cookie.Name = data[0 : len(data)-1]
+	cookie.Name = data[0 : len(data)-1 : len(data)-1]
 
 	return nil
 }
 
 // readPageCookiePath reads and stores the cookie path field.
 func (b *BinaryCookies) readPageCookiePath(cookie *Cookie) error {
-	data := make([]byte, cookie.valueOffset-cookie.pathOffset)
+	data, err := b.readField("path", int(cookie.ValueOffset-cookie.PathOffset))
 
-	if n, err := b.file.Read(data); err != nil {
-		return fmt.Errorf("readPageCookie path text %q; %w", data[:n], err)
+	if err != nil {
+		return err
 	}
 
 	// NOTES(cixtor): fix null-terminated string.
-	cookie.Path = data[0 : len(data)-1]
+	cookie.Path = data[0 : len(data)-1 : len(data)-1]
 
 	return nil
 }
 
 // readPageCookieValue reads and stores the cookie value field.
 func (b *BinaryCookies) readPageCookieValue(cookie *Cookie) error {
-	data := make([]byte, cookie.Size-cookie.valueOffset)
+	data, err := b.readField("value", int(cookie.Size-cookie.ValueOffset))
 
-	if n, err := b.file.Read(data); err != nil {
-		return fmt.Errorf("readPageCookie value text %q; %w", data[:n], err)
+	if err != nil {
+		return err
 	}
 
 	// NOTES(cixtor): fix null-terminated string.
-	cookie.Value = data[0 : len(data)-1]
+	cookie.Value = data[0 : len(data)-1 : len(data)-1]
 
 	return nil
 }
 
-// readChecksum reads and stores the checksum of the entire file.
+// readChecksum reads and stores the checksum of the entire file. The checksum
+// is the sum of every fourth byte of every page, starting with the page tag.
 func (b *BinaryCookies) readChecksum() error {
-	data := make([]byte, 8)
+	data, err := b.readField("checksum", 4)
 
-	if n, err := b.file.Read(data); err != nil {
-		return fmt.Errorf("readChecksum from %q; %w", data[:n], err)
+	if err != nil {
+		return err
+	}
+
+	b.checksum = binary.BigEndian.Uint32(data)
+
+	if b.opts.DisallowInvalidChecksum && b.checksum != b.computed {
+		return b.errorAt("checksum", b.offset-4, fmt.Errorf("%#08x != %#08x; %w", b.checksum, b.computed, ErrChecksum))
 	}
 
-	b.checksum = data
+	return nil
+}
+
+// readFooter reads and validates the bytes following the checksum.
+func (b *BinaryCookies) readFooter() error {
+	data, err := b.readField("footer", 4)
+
+	if err != nil {
+		return err
+	}
+
+	if !bytes.Equal(data, footer) {
+		return b.errorAt("footer", b.offset-4, ErrBadFooter)
+	}
+
+	return nil
+}
+
+// readTrailer reads and stores all the bytes after the checksum, if any.
+func (b *BinaryCookies) readTrailer() error {
+	var buf bytes.Buffer
+
+	offset := b.offset
+
+	if b.zeroCopy {
+		if len(b.input) > 0 {
+			b.trailer = b.input
+			b.offset += int64(len(b.input))
+			b.input = nil
+		}
+
+		return nil
+	}
+
+	n, err := buf.ReadFrom(b.file)
+
+	b.offset += n
+
+	if err != nil {
+		return b.errorAt("trailer", offset, err)
+	}
+
+	if n > 0 {
+		b.trailer = buf.Bytes()
+	}
 
 	return nil
 }
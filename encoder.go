@@ -0,0 +1,276 @@
+package binarycookies
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"time"
+)
+
+// pageTag are the bytes marking the beginning of every page in the archive.
+var pageTag []byte = []byte{0x00, 0x00, 0x01, 0x00}
+
+// footer are the bytes following the checksum at the end of the archive.
+var footer []byte = []byte{0x07, 0x17, 0x20, 0x05}
+
+// cookieHeaderSize is the number of bytes in the fixed part of a cookie, the
+// variable-length strings (comment, domain, name, path and value) start right
+// after the header.
+const cookieHeaderSize = 56
+
+// Encoder writes binary cookies into an output stream.
+type Encoder struct {
+	w       io.Writer
+	trailer []byte
+}
+
+// NewEncoder returns a new encoder that writes to w.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w}
+}
+
+// SetTrailer sets the bytes written after the footer, for example, the ones
+// returned by BinaryCookies.Trailer when re-encoding a decoded file.
+func (e *Encoder) SetTrailer(data []byte) {
+	e.trailer = data
+}
+
+// Encode writes the binary cookies representation of pages into the stream.
+//
+// The number of cookies per page, the cookie offsets and the cookie sizes are
+// computed from the data, which means Page.Length, Page.Offsets, Cookie.Size
+// and the string offsets are ignored, as well as Page.Orphans. The Secure and
+// HttpOnly fields take precedence over the value of the equivalent bits in
+// Cookie.Flags.
+//
+// The offsets follow the order of Page.Cookies. The cookies are written in the
+// order of Cookie.Start if every cookie in the page has a position in the file,
+// as the ones returned by Decode, otherwise in the order of Page.Cookies.
+//
+// Unknown cookie fields and unused bytes found between cookies or at the end
+// of a page in a decoded file are written back as they were, so decoding and
+// encoding a file without modifications produces the exact same bytes, even
+// if the cookies are not stored in the same order as their offsets.
+func (e *Encoder) Encode(pages []Page) error {
+	var buf bytes.Buffer
+
+	data := make([][]byte, len(pages))
+
+	for i, page := range pages {
+		out, err := encodePage(page)
+
+		if err != nil {
+			return fmt.Errorf("encodePage #%d; %w", i, err)
+		}
+
+		data[i] = out
+	}
+
+	buf.Write(magic)
+	writeUint32(&buf, binary.BigEndian, uint32(len(data)))
+
+	for _, page := range data {
+		writeUint32(&buf, binary.BigEndian, uint32(len(page)))
+	}
+
+	for _, page := range data {
+		buf.Write(page)
+	}
+
+	writeUint32(&buf, binary.BigEndian, checksum(data))
+	buf.Write(footer)
+	buf.Write(e.trailer)
+
+	_, err := e.w.Write(buf.Bytes())
+
+	return err
+}
+
+// encodePage returns the binary representation of one page and its cookies.
+func encodePage(page Page) ([]byte, error) {
+	var buf bytes.Buffer
+
+	cookies := make([][]byte, len(page.Cookies))
+
+	for i, cookie := range page.Cookies {
+		out, err := encodeCookie(cookie)
+
+		if err != nil {
+			return nil, fmt.Errorf("encodeCookie #%d; %w", i, err)
+		}
+
+		cookies[i] = out
+	}
+
+	// NOTES(cixtor): the first cookie starts right after the page tag, the
+	// number of cookies, one offset per cookie and the page end marker.
+	offset := uint32(4 + 4 + 4*len(cookies) + 4)
+	offsets := make([]uint32, len(cookies))
+
+	order := cookieOrder(page.Cookies)
+
+	for _, i := range order {
+		offset += uint32(len(page.Cookies[i].slack))
+		offsets[i] = offset
+		offset += uint32(len(cookies[i]))
+	}
+
+	buf.Write(pageTag)
+	writeUint32(&buf, binary.LittleEndian, uint32(len(cookies)))
+
+	for _, n := range offsets {
+		writeUint32(&buf, binary.LittleEndian, n)
+	}
+
+	buf.Write([]byte{0x0, 0x0, 0x0, 0x0})
+
+	for _, i := range order {
+		buf.Write(page.Cookies[i].slack)
+		buf.Write(cookies[i])
+	}
+
+	buf.Write(page.slack)
+
+	return buf.Bytes(), nil
+}
+
+// cookieOrder returns the indexes of the cookies in the order they are written
+// into the page, sorted by their position in the file if all of them have one.
+func cookieOrder(cookies []Cookie) []int {
+	order := make([]int, len(cookies))
+
+	for i := range order {
+		order[i] = i
+	}
+
+	for _, cookie := range cookies {
+		if cookie.Start == 0 {
+			return order
+		}
+	}
+
+	sort.SliceStable(order, func(i, j int) bool {
+		return cookies[order[i]].Start < cookies[order[j]].Start
+	})
+
+	return order
+}
+
+// encodeCookie returns the binary representation of one cookie.
+func encodeCookie(cookie Cookie) ([]byte, error) {
+	var buf bytes.Buffer
+
+	offset := uint32(cookieHeaderSize)
+
+	// NOTES(cixtor): a decoded cookie may have an empty comment, which is not
+	// the same as no comment at all, and is written back to keep its bytes.
+	if len(cookie.Comment) > 0 || cookie.hasComment {
+		cookie.CommentOffset = offset
+		offset += uint32(len(cookie.Comment) + 1)
+	} else {
+		cookie.CommentOffset = 0
+	}
+
+	cookie.DomainOffset = offset
+	offset += uint32(len(cookie.Domain) + 1)
+	cookie.NameOffset = offset
+	offset += uint32(len(cookie.Name) + 1)
+	cookie.PathOffset = offset
+	offset += uint32(len(cookie.Path) + 1)
+	cookie.ValueOffset = offset
+	offset += uint32(len(cookie.Value) + 1)
+	cookie.Size = offset
+
+	if total := cookie.Size - cookie.CommentOffset; total > maxCookieSize {
+		return nil, fmt.Errorf("maximum overall cookie size exceeded %d > %d; %w", total, maxCookieSize, ErrCookieTooLarge)
+	}
+
+	for _, t := range []time.Time{cookie.Expires, cookie.Creation} {
+		if year := t.UTC().Year(); year < 1 || year > 9999 {
+			return nil, fmt.Errorf("%s; %w", t, ErrBadTime)
+		}
+	}
+
+	flags := cookie.Flags &^ (FlagSecure | FlagHttpOnly)
+
+	if cookie.Secure {
+		flags |= FlagSecure
+	}
+
+	if cookie.HttpOnly {
+		flags |= FlagHttpOnly
+	}
+
+	writeUint32(&buf, binary.LittleEndian, cookie.Size)
+	writeUnknown(&buf, cookie.unknownOne)
+	writeUint32(&buf, binary.LittleEndian, uint32(flags))
+	writeUnknown(&buf, cookie.unknownTwo)
+	writeUint32(&buf, binary.LittleEndian, cookie.DomainOffset)
+	writeUint32(&buf, binary.LittleEndian, cookie.NameOffset)
+	writeUint32(&buf, binary.LittleEndian, cookie.PathOffset)
+	writeUint32(&buf, binary.LittleEndian, cookie.ValueOffset)
+	writeUint32(&buf, binary.LittleEndian, cookie.CommentOffset)
+	buf.Write([]byte{0x0, 0x0, 0x0, 0x0})
+	writeTime(&buf, cookie.Expires)
+	writeTime(&buf, cookie.Creation)
+
+	if cookie.CommentOffset != 0 {
+		writeString(&buf, cookie.Comment)
+	}
+
+	writeString(&buf, cookie.Domain)
+	writeString(&buf, cookie.Name)
+	writeString(&buf, cookie.Path)
+	writeString(&buf, cookie.Value)
+
+	return buf.Bytes(), nil
+}
+
+// checksum returns the checksum of the archive, which is the sum of every
+// fourth byte of every page, starting with the first byte of the page tag.
+func checksum(pages [][]byte) uint32 {
+	var sum uint32
+
+	for _, page := range pages {
+		for i := 0; i < len(page); i += 4 {
+			sum += uint32(page[i])
+		}
+	}
+
+	return sum
+}
+
+// writeUint32 writes a 4-bytes integer using the given byte order.
+func writeUint32(buf *bytes.Buffer, order binary.ByteOrder, n uint32) {
+	data := make([]byte, 4)
+	order.PutUint32(data, n)
+	buf.Write(data)
+}
+
+// writeUnknown writes one of the unknown cookie fields, or zeroes if the field
+// was never decoded, for example, when the cookie was created by hand.
+func writeUnknown(buf *bytes.Buffer, data []byte) {
+	if len(data) != 4 {
+		data = []byte{0x0, 0x0, 0x0, 0x0}
+	}
+
+	buf.Write(data)
+}
+
+// writeTime converts a Unix timestamp into Mac epoch time and writes it. The
+// fractional seconds are kept with the precision allowed by a float64.
+func writeTime(buf *bytes.Buffer, t time.Time) {
+	data := make([]byte, 8)
+	number := float64(t.Unix()-int64(timePadding)) + float64(t.Nanosecond())/1e9
+	binary.LittleEndian.PutUint64(data, math.Float64bits(number))
+	buf.Write(data)
+}
+
+// writeString writes a null-terminated string.
+func writeString(buf *bytes.Buffer, data []byte) {
+	buf.Write(data)
+	buf.WriteByte(0x0)
+}
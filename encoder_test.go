@@ -0,0 +1,312 @@
+package binarycookies
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestEncodeDecoded(t *testing.T) {
+	pages, err := New(bytes.NewReader(_test2)).Decode()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+
+	if err := NewEncoder(&buf).Encode(pages); err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(buf.Bytes(), _test2) {
+		t.Fatalf("incorrect encoding\n- %#v\n+ %#v", _test2, buf.Bytes())
+	}
+}
+
+func TestEncodeChecksum(t *testing.T) {
+	pages, err := New(bytes.NewReader(_test1)).Decode()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+
+	if err := NewEncoder(&buf).Encode(pages); err != nil {
+		t.Fatal(err)
+	}
+
+	// NOTES(cixtor): the fixture has a binary property list after the footer.
+	expected := _test1[:len(_test1)-79]
+
+	if !bytes.Equal(buf.Bytes(), expected) {
+		t.Fatalf("incorrect encoding\n- %#v\n+ %#v", expected, buf.Bytes())
+	}
+}
+
+func TestEncodeNewCookies(t *testing.T) {
+	expires := time.Date(2030, time.January, 2, 3, 4, 5, 0, time.UTC)
+	creation := time.Date(2020, time.January, 2, 3, 4, 5, 0, time.UTC)
+
+	pages := []Page{
+		{
+			Cookies: []Cookie{
+				{
+					Secure:   true,
+					HttpOnly: true,
+					Comment:  []byte(`session identifier`),
+					Domain:   []byte(`.example.com`),
+					Name:     []byte(`sid`),
+					Path:     []byte(`/`),
+					Value:    []byte(`abc123`),
+					Expires:  expires,
+					Creation: creation,
+				},
+				{
+					Domain:   []byte(`www.example.com`),
+					Name:     []byte(`lang`),
+					Path:     []byte(`/docs`),
+					Value:    []byte(`en`),
+					Expires:  expires,
+					Creation: creation,
+				},
+			},
+		},
+		{},
+	}
+
+	var buf bytes.Buffer
+
+	if err := NewEncoder(&buf).Encode(pages); err != nil {
+		t.Fatal(err)
+	}
+
+	checkCookiePage(t, buf.Bytes(), 0, Page{
+		Length:  2,
+		Offsets: []uint32{20, 121},
+		Cookies: []Cookie{
+			{
+				Size:     101,
+				Secure:   true,
+				HttpOnly: true,
+				Comment:  []byte(`session identifier`),
+				Domain:   []byte(`.example.com`),
+				Name:     []byte(`sid`),
+				Path:     []byte(`/`),
+				Value:    []byte(`abc123`),
+			},
+			{
+				Size:   86,
+				Domain: []byte(`www.example.com`),
+				Name:   []byte(`lang`),
+				Path:   []byte(`/docs`),
+				Value:  []byte(`en`),
+			},
+		},
+	})
+
+	checkCookiePage(t, buf.Bytes(), 1, Page{Length: 0, Offsets: []uint32{}, Cookies: []Cookie{}})
+
+	out, err := New(bytes.NewReader(buf.Bytes())).Decode()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !out[0].Cookies[0].Expires.Equal(expires) {
+		t.Fatalf("incorrect expiration time\n- %s\n+ %s", expires, out[0].Cookies[0].Expires)
+	}
+
+	if !out[0].Cookies[0].Creation.Equal(creation) {
+		t.Fatalf("incorrect creation time\n- %s\n+ %s", creation, out[0].Cookies[0].Creation)
+	}
+}
+
+func TestEncodeCookieTooLarge(t *testing.T) {
+	pages := []Page{{Cookies: []Cookie{{Value: make([]byte, maxCookieSize)}}}}
+
+	if err := NewEncoder(&bytes.Buffer{}).Encode(pages); !errors.Is(err, ErrCookieTooLarge) {
+		t.Fatalf("cookies larger than %d bytes should return %s, got %v", maxCookieSize, ErrCookieTooLarge, err)
+	}
+}
+
+func TestEncodeEmptyComment(t *testing.T) {
+	var buf bytes.Buffer
+
+	pages := []Page{{Cookies: []Cookie{{Comment: []byte{}, hasComment: true, Domain: []byte(`example.com`)}}}}
+
+	if err := NewEncoder(&buf).Encode(pages); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := New(bytes.NewReader(buf.Bytes())).Decode()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cookie := out[0].Cookies[0]; cookie.CommentOffset != cookieHeaderSize || len(cookie.Comment) != 0 {
+		t.Fatalf("incorrect empty comment at offset %d\n+ %q", cookie.CommentOffset, cookie.Comment)
+	}
+
+	var again bytes.Buffer
+
+	if err := NewEncoder(&again).Encode(out); err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(again.Bytes(), buf.Bytes()) {
+		t.Fatalf("incorrect encoding\n- %#v\n+ %#v", buf.Bytes(), again.Bytes())
+	}
+}
+
+func TestEncodeTimePrecision(t *testing.T) {
+	// NOTES(cixtor): a float64 holds around 16 significant digits, which is
+	// enough for microseconds in a timestamp from this century.
+	expires := time.Date(2030, 5, 17, 8, 30, 15, 123456000, time.UTC)
+	creation := time.Date(1999, 12, 31, 23, 59, 59, 999999000, time.UTC)
+	pages := []Page{{Cookies: []Cookie{{Expires: expires, Creation: creation}}}}
+
+	var buf bytes.Buffer
+
+	if err := NewEncoder(&buf).Encode(pages); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := New(bytes.NewReader(buf.Bytes())).Decode()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := out[0].Cookies[0].Expires.Round(time.Microsecond); !got.Equal(expires) {
+		t.Fatalf("incorrect expiration time\n- %s\n+ %s", expires, got)
+	}
+
+	if got := out[0].Cookies[0].Creation.Round(time.Microsecond); !got.Equal(creation) {
+		t.Fatalf("incorrect creation time\n- %s\n+ %s", creation, got)
+	}
+
+	// NOTES(cixtor): decoding and encoding the timestamps again must produce
+	// the exact same bytes, fractional seconds included.
+	var again bytes.Buffer
+
+	if err := NewEncoder(&again).Encode(out); err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(again.Bytes(), buf.Bytes()) {
+		t.Fatalf("incorrect encoding\n- %#v\n+ %#v", buf.Bytes(), again.Bytes())
+	}
+}
+
+func TestEncodeTimeOutOfRange(t *testing.T) {
+	expires := time.Date(10000, 1, 1, 0, 0, 0, 0, time.UTC)
+	pages := []Page{{Cookies: []Cookie{{Expires: expires}}}}
+
+	if err := NewEncoder(&bytes.Buffer{}).Encode(pages); !errors.Is(err, ErrBadTime) {
+		t.Fatalf("timestamp outside the years 1 to 9999 should return %s, got %v", ErrBadTime, err)
+	}
+}
+
+func TestEncodeRoundTrip(t *testing.T) {
+	cook := New(bytes.NewReader(_test1))
+
+	pages, err := cook.Decode()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+
+	enc := NewEncoder(&buf)
+	enc.SetTrailer(cook.Trailer())
+
+	if err := enc.Encode(pages); err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(buf.Bytes(), _test1) {
+		t.Fatalf("incorrect encoding\n- %#v\n+ %#v", _test1, buf.Bytes())
+	}
+}
+
+func TestEncodeRoundTripSlack(t *testing.T) {
+	pages := []Page{
+		{
+			Cookies: []Cookie{
+				{
+					unknownOne: []byte{0x1, 0x2, 0x3, 0x4},
+					unknownTwo: []byte{0x5, 0x6, 0x7, 0x8},
+					Domain:     []byte(`.example.com`),
+					Name:       []byte(`a`),
+					Path:       []byte(`/`),
+					Value:      []byte(`1`),
+				},
+				{
+					Domain: []byte(`.example.com`),
+					Name:   []byte(`b`),
+					Path:   []byte(`/`),
+					Value:  []byte(`2`),
+					slack:  []byte(`stale cookie bytes`),
+				},
+			},
+			slack: []byte{0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0},
+		},
+	}
+
+	var first bytes.Buffer
+
+	enc := NewEncoder(&first)
+	enc.SetTrailer(_test1[len(_test1)-79:])
+
+	if err := enc.Encode(pages); err != nil {
+		t.Fatal(err)
+	}
+
+	checkCookiePage(t, first.Bytes(), 0, Page{Length: 2, Offsets: []uint32{20, 113}})
+
+	cook := New(bytes.NewReader(first.Bytes()))
+
+	out, err := cook.Decode()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var second bytes.Buffer
+
+	enc = NewEncoder(&second)
+	enc.SetTrailer(cook.Trailer())
+
+	if err := enc.Encode(out); err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(first.Bytes(), second.Bytes()) {
+		t.Fatalf("incorrect encoding\n- %#v\n+ %#v", first.Bytes(), second.Bytes())
+	}
+}
+
+func TestEncodeRoundTripReordered(t *testing.T) {
+	data := reorderedFile(t)
+
+	pages, err := New(bytes.NewReader(data)).Decode()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+
+	if err := NewEncoder(&buf).Encode(pages); err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(buf.Bytes(), data) {
+		t.Fatalf("incorrect encoding\n- %#v\n+ %#v", data, buf.Bytes())
+	}
+}
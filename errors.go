@@ -0,0 +1,81 @@
+package binarycookies
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+var (
+	// ErrBadSignature means the file does not start with the "cook" signature.
+	ErrBadSignature = errors.New("invalid signature")
+	// ErrBadPageTag means a page does not start with the 0x00000100 tag.
+	ErrBadPageTag = errors.New("invalid page tag")
+	// ErrBadPageEnd means the cookie offsets are not followed by 0x00000000.
+	ErrBadPageEnd = errors.New("invalid page end")
+	// ErrBadEndHeader means a cookie header does not end with 0x00000000.
+	ErrBadEndHeader = errors.New("invalid cookie end header")
+	// ErrBadOffset means an offset points outside of its page or cookie, or
+	// the cookie strings are not stored in order.
+	ErrBadOffset = errors.New("invalid offset")
+	// ErrCookieTooLarge means a cookie exceeds the maximum cookie size.
+	ErrCookieTooLarge = errors.New("cookie too large")
+	// ErrNoTerminator means a cookie string does not end with a null byte.
+	// Decode accepts these strings, only Validate reports them.
+	ErrNoTerminator = errors.New("missing null terminator")
+	// ErrBadTime means a cookie timestamp is not a number, is infinite, or is
+	// outside the years 1 to 9999.
+	ErrBadTime = errors.New("invalid timestamp")
+	// ErrChecksum means the checksum stored in the file does not match the
+	// checksum of the pages, returned only after DisallowInvalidChecksum.
+	ErrChecksum = errors.New("checksum mismatch")
+	// ErrBadFooter means the checksum is not followed by 0x07172005.
+	ErrBadFooter = errors.New("invalid footer")
+	// ErrBadTrailer means the bytes after the footer are not a valid binary
+	// property list prefixed by its size.
+	ErrBadTrailer = errors.New("invalid trailer")
+	// ErrBadNetscape means a line in a Netscape cookie file does not have the
+	// expected fields.
+	ErrBadNetscape = errors.New("invalid Netscape cookie line")
+	// ErrLimitExceeded means the file exceeds one of the limits set with
+	// DecoderOptions.
+	ErrLimitExceeded = errors.New("limit exceeded")
+	// ErrTruncated means the file ended before a field could be read. It is
+	// the same value as io.ErrUnexpectedEOF.
+	ErrTruncated = io.ErrUnexpectedEOF
+)
+
+// DecodeError describes a part of the file that could not be decoded. Use
+// errors.Is with one of the sentinel errors to find out what went wrong.
+type DecodeError struct {
+	// Field is the name of the field that could not be decoded, for example,
+	// "page tag" or "domain", or "cookie" when the cookie is invalid as a whole.
+	Field string
+	// Page is the index of the page, or -1 if the error is outside the pages.
+	Page int
+	// Cookie is the index of the cookie in the page, or -1 if the error is not
+	// related to a specific cookie.
+	Cookie int
+	// Offset is the position in the file where the failing field starts.
+	Offset int64
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *DecodeError) Error() string {
+	msg := fmt.Sprintf("%s at offset %d", e.Field, e.Offset)
+
+	if e.Page >= 0 {
+		msg += fmt.Sprintf(" page #%d", e.Page)
+	}
+
+	if e.Cookie >= 0 {
+		msg += fmt.Sprintf(" cookie #%d", e.Cookie)
+	}
+
+	return msg + "; " + e.Err.Error()
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
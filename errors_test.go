@@ -0,0 +1,85 @@
+package binarycookies
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func checkDecodeError(t *testing.T, data []byte, target error, expected DecodeError) {
+	_, err := New(bytes.NewReader(data)).Decode()
+
+	if !errors.Is(err, target) {
+		t.Fatalf("incorrect error\n- %s\n+ %v", target, err)
+	}
+
+	var e *DecodeError
+
+	if !errors.As(err, &e) {
+		t.Fatalf("error is not a DecodeError %#v", err)
+	}
+
+	if e.Field != expected.Field || e.Page != expected.Page || e.Cookie != expected.Cookie || e.Offset != expected.Offset {
+		t.Fatalf("incorrect error position\n- %q page #%d cookie #%d at offset %d\n+ %q page #%d cookie #%d at offset %d",
+			expected.Field, expected.Page, expected.Cookie, expected.Offset,
+			e.Field, e.Page, e.Cookie, e.Offset)
+	}
+}
+
+func corrupt(data []byte, offset int, values ...byte) []byte {
+	out := append([]byte{}, data...)
+	copy(out[offset:], values)
+	return out
+}
+
+func TestDecodeErrorSignature(t *testing.T) {
+	checkDecodeError(t, _test3, ErrBadSignature, DecodeError{Field: "signature", Page: -1, Cookie: -1, Offset: 0})
+}
+
+func TestDecodeErrorPageTag(t *testing.T) {
+	data := corrupt(_test1, 466, 0xff)
+	checkDecodeError(t, data, ErrBadPageTag, DecodeError{Field: "page tag", Page: 2, Cookie: -1, Offset: 466})
+}
+
+func TestDecodeErrorPageEnd(t *testing.T) {
+	data := corrupt(_test1, 64+24, 0xff)
+	checkDecodeError(t, data, ErrBadPageEnd, DecodeError{Field: "page end", Page: 1, Cookie: -1, Offset: 88})
+}
+
+func TestDecodeErrorEndHeader(t *testing.T) {
+	data := corrupt(_test1, 185+36, 0xff)
+	checkDecodeError(t, data, ErrBadEndHeader, DecodeError{Field: "end header", Page: 1, Cookie: 1, Offset: 221})
+}
+
+func TestDecodeErrorCookieTooLarge(t *testing.T) {
+	data := corrupt(_test1, 185, 0xff, 0xff)
+	checkDecodeError(t, data, ErrCookieTooLarge, DecodeError{Field: "cookie", Page: 1, Cookie: 1, Offset: 185})
+}
+
+func TestDecodeErrorOffsets(t *testing.T) {
+	data := corrupt(_test1, 185+20, 0x38)
+	checkDecodeError(t, data, ErrBadOffset, DecodeError{Field: "cookie", Page: 1, Cookie: 1, Offset: 185})
+}
+
+func TestDecodeErrorFooter(t *testing.T) {
+	data := corrupt(_test2, len(_test2)-4, 0xff)
+	checkDecodeError(t, data, ErrBadFooter, DecodeError{Field: "footer", Page: -1, Cookie: -1, Offset: int64(len(_test2) - 4)})
+}
+
+func TestDecodeErrorTruncated(t *testing.T) {
+	checkDecodeError(t, _test2[:301], ErrTruncated, DecodeError{Field: "domain", Page: 0, Cookie: 2, Offset: 301})
+}
+
+func TestDecodeErrorTime(t *testing.T) {
+	// NOTES(cixtor): the expiration time of the second cookie in page #1 is a
+	// NaN, then its creation time is infinite, then its expiration time is
+	// beyond the year 9999.
+	data := corrupt(_test1, 185+40, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0xf8, 0x7f)
+	checkDecodeError(t, data, ErrBadTime, DecodeError{Field: "expires", Page: 1, Cookie: 1, Offset: 225})
+
+	data = corrupt(_test1, 185+48, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xf0, 0x7f)
+	checkDecodeError(t, data, ErrBadTime, DecodeError{Field: "creation", Page: 1, Cookie: 1, Offset: 233})
+
+	data = corrupt(_test1, 185+40, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xe0, 0x43)
+	checkDecodeError(t, data, ErrBadTime, DecodeError{Field: "expires", Page: 1, Cookie: 1, Offset: 225})
+}
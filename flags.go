@@ -0,0 +1,77 @@
+package binarycookies
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Flags is the bitmask stored in every cookie with its attributes. Bits that
+// are not known are kept as they are, so a decoded cookie is encoded with the
+// same flags it had in the file.
+//
+// Whether a cookie is host-only is not stored in the flags, see Cookie.HostOnly.
+//
+// NOTES(cixtor): only Secure and HttpOnly are confirmed by every file seen so
+// far. Other bits may store attributes like SameSite or Partitioned, but no
+// WebKit or CFNetwork source confirms their values, so they are reported by
+// Unknown and never set by this package.
+type Flags uint32
+
+const (
+	// FlagSecure means the cookie is only sent over secure connections.
+	FlagSecure Flags = 0x1
+	// FlagHttpOnly means the cookie is not available to scripts.
+	FlagHttpOnly Flags = 0x4
+)
+
+// knownFlags is the union of all the named flags.
+const knownFlags = FlagSecure | FlagHttpOnly
+
+// flagNames is the name of every known flag, in the order they are printed.
+var flagNames = []struct {
+	flag Flags
+	name string
+}{
+	{FlagSecure, "Secure"},
+	{FlagHttpOnly, "HttpOnly"},
+}
+
+// Has reports whether all the bits in flag are set.
+func (f Flags) Has(flag Flags) bool {
+	return f&flag == flag
+}
+
+// Secure reports whether the FlagSecure bit is set.
+func (f Flags) Secure() bool {
+	return f.Has(FlagSecure)
+}
+
+// HttpOnly reports whether the FlagHttpOnly bit is set.
+func (f Flags) HttpOnly() bool {
+	return f.Has(FlagHttpOnly)
+}
+
+// Unknown returns the bits that do not correspond to any named flag.
+func (f Flags) Unknown() Flags {
+	return f &^ knownFlags
+}
+
+func (f Flags) String() string {
+	var names []string
+
+	for _, n := range flagNames {
+		if f.Has(n.flag) {
+			names = append(names, n.name)
+		}
+	}
+
+	if unknown := f.Unknown(); unknown != 0 {
+		names = append(names, fmt.Sprintf("%#x", uint32(unknown)))
+	}
+
+	if len(names) == 0 {
+		return "None"
+	}
+
+	return strings.Join(names, "|")
+}
@@ -0,0 +1,76 @@
+package binarycookies
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestFlagsString(t *testing.T) {
+	tests := map[Flags]string{
+		0:                         "None",
+		FlagSecure:                "Secure",
+		FlagSecure | FlagHttpOnly: "Secure|HttpOnly",
+		FlagHttpOnly | 0x80:       "HttpOnly|0x80",
+		FlagSecure | 0x308:        "Secure|0x308",
+	}
+
+	for flags, expected := range tests {
+		if s := flags.String(); s != expected {
+			t.Fatalf("incorrect flags %#x\n- %s\n+ %s", uint32(flags), expected, s)
+		}
+	}
+}
+
+func TestDecodeFlags(t *testing.T) {
+	// NOTES(cixtor): the second cookie in page #1 is Secure and HttpOnly, add
+	// three unknown bits.
+	data := corrupt(_test1, 185+8, 0x4d, 0x01)
+	cook := New(bytes.NewReader(data))
+
+	pages, err := cook.Decode()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flags := pages[1].Cookies[1].Flags
+
+	if flags != 0x14d {
+		t.Fatalf("incorrect flags\n- %#x\n+ %#x", 0x14d, uint32(flags))
+	}
+
+	if !pages[1].Cookies[1].Secure || !pages[1].Cookies[1].HttpOnly {
+		t.Fatalf("cookie with flags %s should be Secure and HttpOnly", flags)
+	}
+
+	if flags.Unknown() != 0x148 {
+		t.Fatalf("incorrect unknown flags\n- %#x\n+ %#x", 0x148, uint32(flags.Unknown()))
+	}
+
+	var buf bytes.Buffer
+
+	enc := NewEncoder(&buf)
+	enc.SetTrailer(cook.Trailer())
+
+	if err := enc.Encode(pages); err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(buf.Bytes(), data) {
+		t.Fatalf("unknown flags should be encoded as they were found")
+	}
+}
+
+func TestCookieHostOnly(t *testing.T) {
+	tests := map[string]bool{
+		"":             true,
+		"example.com":  true,
+		".example.com": false,
+	}
+
+	for domain, expected := range tests {
+		if hostOnly := (Cookie{Domain: []byte(domain)}).HostOnly(); hostOnly != expected {
+			t.Fatalf("incorrect HostOnly for %q\n- %t\n+ %t", domain, expected, hostOnly)
+		}
+	}
+}
@@ -13,5 +13,35 @@ func FuzzTest(f *testing.F) {
 		out, err := New(bytes.NewReader(a)).Decode()
 		t.Logf("out: %#v\n", out)
 		t.Logf("err: %s\n", err)
+
+		cook := New(bytes.NewReader(a))
+		cook.RecoverCorrupted()
+		out, err = cook.Decode()
+		t.Logf("out: %#v\n", out)
+		t.Logf("err: %s\n", err)
+
+		out, err = DecodeBytes(a)
+		t.Logf("out: %#v\n", out)
+		t.Logf("err: %s\n", err)
+
+		out, err = NewDecoder(bytes.NewReader(a), &DecoderOptions{Workers: 2}).Decode()
+		t.Logf("out: %#v\n", out)
+		t.Logf("err: %s\n", err)
+
+		out, err = NewDecoder(bytes.NewReader(a), &DecoderOptions{RecoverSlack: true, RecoverCorrupted: true}).Decode()
+		t.Logf("out: %#v\n", out)
+		t.Logf("err: %s\n", err)
+
+		stats, err := Stat(bytes.NewReader(a))
+		t.Logf("stats: %#v\n", stats)
+		t.Logf("err: %s\n", err)
+
+		t.Logf("findings: %v\n", Validate(a))
+
+		carver := NewCarver(bytes.NewReader(a))
+		for carver.Next() {
+			t.Logf("carved: %d %s\n", carver.Cookie().Start, carver.Cookie())
+		}
+		t.Logf("err: %v\n", carver.Err())
 	})
 }
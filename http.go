@@ -0,0 +1,61 @@
+package binarycookies
+
+import (
+	"net/http"
+	"strings"
+)
+
+// ToHTTPCookie returns the cookie as an http.Cookie, with the same name,
+// value, path, expiration time, Secure and HttpOnly attributes.
+//
+// The domain keeps the leading dot of cookies that include subdomains, and
+// host-only cookies have the host as the domain, which is not the same as a
+// Set-Cookie header without a Domain attribute but allows to tell both kinds
+// apart. Session cookies have a zero Expires time.
+//
+// The conversion loses the comment, the creation time, the unknown flags and
+// fields, and the positions in the file. SameSite is never set, the bits that
+// store it in the file are not known yet.
+func (c Cookie) ToHTTPCookie() *http.Cookie {
+	return &http.Cookie{
+		Name:     string(c.Name),
+		Value:    string(c.Value),
+		Path:     string(c.Path),
+		Domain:   string(c.Domain),
+		Expires:  c.Expires,
+		Secure:   c.Secure,
+		HttpOnly: c.HttpOnly,
+	}
+}
+
+// FromHTTPCookie returns the http.Cookie as a cookie. A domain starting with a
+// dot means the cookie includes subdomains, otherwise it is a host-only cookie,
+// the opposite of ToHTTPCookie. The Secure and HttpOnly attributes are stored
+// in Flags as well.
+//
+// The conversion loses MaxAge, which is relative to the time the cookie was
+// received, SameSite, and the attributes not supported by the file format.
+// The comment and the creation time are empty. Use Jar.SetCookies to store
+// the cookies of a response, which applies the rules of RFC 6265 to the
+// domain, the path and the expiration time.
+func FromHTTPCookie(c *http.Cookie) Cookie {
+	cookie := Cookie{
+		Name:     []byte(c.Name),
+		Value:    []byte(c.Value),
+		Path:     []byte(c.Path),
+		Domain:   []byte(strings.ToLower(c.Domain)),
+		Expires:  c.Expires,
+		Secure:   c.Secure,
+		HttpOnly: c.HttpOnly,
+	}
+
+	if c.Secure {
+		cookie.Flags |= FlagSecure
+	}
+
+	if c.HttpOnly {
+		cookie.Flags |= FlagHttpOnly
+	}
+
+	return cookie
+}
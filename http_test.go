@@ -0,0 +1,65 @@
+package binarycookies
+
+import (
+	"bytes"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestToHTTPCookie(t *testing.T) {
+	pages, err := New(bytes.NewReader(_test1)).Decode()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cookie := pages[1].Cookies[1]
+
+	expected := &http.Cookie{
+		Name:     "httpOnlySecure",
+		Value:    "value",
+		Path:     "/",
+		Domain:   "urlecho.appspot.com",
+		Expires:  cookie.Expires,
+		Secure:   true,
+		HttpOnly: true,
+	}
+
+	if c := cookie.ToHTTPCookie(); !reflect.DeepEqual(c, expected) {
+		t.Fatalf("incorrect HTTP cookie\n- %#v\n+ %#v", expected, c)
+	}
+}
+
+func TestFromHTTPCookie(t *testing.T) {
+	expires := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		cookie   *http.Cookie
+		expected Cookie
+	}{
+		{
+			&http.Cookie{Name: "a", Value: "1", Path: "/", Domain: ".Example.com", Expires: expires, Secure: true, SameSite: http.SameSiteStrictMode},
+			Cookie{Name: []byte("a"), Value: []byte("1"), Path: []byte("/"), Domain: []byte(".example.com"), Expires: expires, Secure: true, Flags: FlagSecure},
+		},
+		{
+			&http.Cookie{Name: "b", Value: "2", Path: "/account", Domain: "www.example.com", HttpOnly: true, MaxAge: 60},
+			Cookie{Name: []byte("b"), Value: []byte("2"), Path: []byte("/account"), Domain: []byte("www.example.com"), HttpOnly: true, Flags: FlagHttpOnly},
+		},
+	}
+
+	for _, test := range tests {
+		cookie := FromHTTPCookie(test.cookie)
+
+		if !reflect.DeepEqual(cookie, test.expected) {
+			t.Fatalf("incorrect cookie\n- %#v\n+ %#v", test.expected, cookie)
+		}
+
+		c := cookie.ToHTTPCookie()
+
+		if c.Domain != string(test.expected.Domain) || c.SameSite != 0 || c.Secure != test.cookie.Secure || c.HttpOnly != test.cookie.HttpOnly {
+			t.Fatalf("incorrect round trip\n- %#v\n+ %#v", test.cookie, c)
+		}
+	}
+}
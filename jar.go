@@ -0,0 +1,309 @@
+package binarycookies
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Jar is an http.CookieJar backed by the cookies of a binary cookies file,
+// which allows an HTTP client to reuse the sessions of Safari and of other
+// applications using a web view.
+//
+// Cookies follows the rules in RFC 6265 to select the cookies sent to a URL:
+// the host must match the domain of the cookie, or be a subdomain if the
+// domain starts with a dot, the path must match the path of the cookie, secure
+// cookies are only sent over HTTPS and expired cookies are never sent.
+//
+// SetCookies follows the same RFC to store the cookies of a response, except
+// that the public suffix list is not available, so the only domains rejected
+// are the ones without a dot, for example, "com".
+//
+// Jar is safe for concurrent use by multiple goroutines.
+type Jar struct {
+	mu      sync.Mutex
+	cookies []Cookie
+	now     func() time.Time
+}
+
+var _ http.CookieJar = (*Jar)(nil)
+
+// NewJar returns a Jar with the cookies in pages, usually the ones returned by
+// Decode. The cookies are copied, including their strings, so pages can be
+// modified after the call, and the jar remains valid after closing the file
+// mapped with OpenMapped.
+func NewJar(pages []Page) *Jar {
+	j := &Jar{now: time.Now}
+
+	for _, page := range pages {
+		for _, cookie := range page.Cookies {
+			j.cookies = append(j.cookies, cloneCookie(cookie))
+		}
+	}
+
+	return j
+}
+
+// cloneCookie returns a copy of the cookie that does not share any bytes with
+// the original, which may alias the input of the decoder.
+func cloneCookie(c Cookie) Cookie {
+	for _, field := range []*[]byte{&c.unknownOne, &c.unknownTwo, &c.Comment, &c.Domain, &c.Name, &c.Path, &c.Value, &c.slack} {
+		if *field != nil {
+			*field = append([]byte{}, *field...)
+		}
+	}
+
+	return c
+}
+
+// Cookies returns the cookies to send in a request to u, with the cookies with
+// longer paths first. Only the name and the value of each cookie are set.
+func (j *Jar) Cookies(u *url.URL) []*http.Cookie {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil
+	}
+
+	host := canonicalHost(u.Host)
+	path := u.EscapedPath()
+
+	if path == "" {
+		path = "/"
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	now := j.now()
+
+	var matches []Cookie
+
+	for _, cookie := range j.cookies {
+		if isExpired(cookie, now) {
+			continue
+		}
+
+		if cookie.Secure && u.Scheme != "https" {
+			continue
+		}
+
+		if !domainMatch(host, string(cookie.Domain)) || !pathMatch(path, string(cookie.Path)) {
+			continue
+		}
+
+		matches = append(matches, cookie)
+	}
+
+	// NOTES(cixtor): RFC 6265 section 5.4 recommends to send the cookies with
+	// longer paths first, and the older cookies first if the paths have the
+	// same length.
+	sort.SliceStable(matches, func(a, b int) bool {
+		if len(matches[a].Path) != len(matches[b].Path) {
+			return len(matches[a].Path) > len(matches[b].Path)
+		}
+
+		return matches[a].Creation.Before(matches[b].Creation)
+	})
+
+	cookies := make([]*http.Cookie, len(matches))
+
+	for i, cookie := range matches {
+		cookies[i] = &http.Cookie{Name: string(cookie.Name), Value: string(cookie.Value)}
+	}
+
+	return cookies
+}
+
+// SetCookies stores the cookies of a response from u, replacing the cookies
+// with the same name, domain and path. Cookies with a negative MaxAge or with
+// an expiration time in the past remove the stored cookie.
+func (j *Jar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return
+	}
+
+	host := canonicalHost(u.Host)
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	now := j.now()
+
+	for _, c := range cookies {
+		cookie, ok := newJarCookie(c, host, u.EscapedPath(), now)
+
+		if !ok {
+			continue
+		}
+
+		j.setCookie(cookie, now)
+	}
+}
+
+// setCookie replaces the cookie with the same name, domain and path, keeping
+// its creation time, or adds the cookie if there is none. Expired cookies are
+// removed instead.
+func (j *Jar) setCookie(cookie Cookie, now time.Time) {
+	for i, old := range j.cookies {
+		if string(old.Name) != string(cookie.Name) || string(old.Domain) != string(cookie.Domain) || string(old.Path) != string(cookie.Path) {
+			continue
+		}
+
+		if isExpired(cookie, now) {
+			j.cookies = append(j.cookies[:i], j.cookies[i+1:]...)
+			return
+		}
+
+		cookie.Creation = old.Creation
+		j.cookies[i] = cookie
+
+		return
+	}
+
+	if !isExpired(cookie, now) {
+		j.cookies = append(j.cookies, cookie)
+	}
+}
+
+// Pages returns the persistent cookies in the jar, grouped in one page per
+// domain. Session cookies, without an expiration time, and expired cookies are
+// not included, the same as Safari does when it saves the cookies.
+func (j *Jar) Pages() []Page {
+	var pages []Page
+
+	index := map[string]int{}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	now := j.now()
+
+	for _, cookie := range j.cookies {
+		if cookie.Expires.IsZero() || isExpired(cookie, now) {
+			continue
+		}
+
+		domain := strings.TrimPrefix(string(cookie.Domain), ".")
+
+		if _, found := index[domain]; !found {
+			index[domain] = len(pages)
+			pages = append(pages, Page{})
+		}
+
+		page := &pages[index[domain]]
+		page.Cookies = append(page.Cookies, cookie)
+		page.Length++
+	}
+
+	return pages
+}
+
+// Save writes the persistent cookies in the jar into w as a binary cookies
+// file. See Pages.
+func (j *Jar) Save(w io.Writer) error {
+	return NewEncoder(w).Encode(j.Pages())
+}
+
+// newJarCookie returns the cookie to store for a Set-Cookie header received
+// from the given host and path, and false if the cookie must be ignored.
+func newJarCookie(c *http.Cookie, host string, path string, now time.Time) (Cookie, bool) {
+	if c.Name == "" {
+		return Cookie{}, false
+	}
+
+	cookie := FromHTTPCookie(c)
+	cookie.Creation = now
+
+	// NOTES(cixtor): Max-Age takes precedence over Expires, and a cookie with
+	// neither is a session cookie, with a zero expiration time.
+	if c.MaxAge < 0 {
+		cookie.Expires = now.Add(-time.Second)
+	} else if c.MaxAge > 0 {
+		cookie.Expires = now.Add(time.Duration(c.MaxAge) * time.Second)
+	}
+
+	if c.Path == "" || c.Path[0] != '/' {
+		cookie.Path = []byte(defaultPath(path))
+	}
+
+	domain := strings.ToLower(strings.TrimPrefix(c.Domain, "."))
+
+	switch {
+	case domain == "" || domain == host:
+		// NOTES(cixtor): a cookie without a Domain attribute is only sent to
+		// the same host, and an IP address cannot have subdomains.
+		if domain == "" || net.ParseIP(host) != nil {
+			cookie.Domain = []byte(host)
+		} else {
+			cookie.Domain = []byte("." + domain)
+		}
+	case strings.HasSuffix(host, "."+domain) && strings.Contains(domain, ".") && net.ParseIP(host) == nil:
+		cookie.Domain = []byte("." + domain)
+	default:
+		return Cookie{}, false
+	}
+
+	return cookie, true
+}
+
+// canonicalHost returns the host without the port and in lower case.
+func canonicalHost(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	return strings.ToLower(strings.TrimSuffix(host, "."))
+}
+
+// isExpired reports whether the cookie has an expiration time before now.
+// Session cookies never expire.
+func isExpired(cookie Cookie, now time.Time) bool {
+	return !cookie.Expires.IsZero() && !cookie.Expires.After(now)
+}
+
+// domainMatch reports whether the host matches the domain of a cookie, as in
+// RFC 6265 section 5.1.3. A domain starting with a dot also matches all its
+// subdomains, otherwise the host must be the same.
+func domainMatch(host string, domain string) bool {
+	domain = strings.ToLower(domain)
+
+	if !strings.HasPrefix(domain, ".") {
+		return host == domain
+	}
+
+	return host == domain[1:] || strings.HasSuffix(host, domain)
+}
+
+// pathMatch reports whether the request path matches the path of a cookie, as
+// in RFC 6265 section 5.1.4.
+func pathMatch(path string, cookiePath string) bool {
+	if cookiePath == "" {
+		cookiePath = "/"
+	}
+
+	if !strings.HasPrefix(path, cookiePath) {
+		return false
+	}
+
+	return len(path) == len(cookiePath) || strings.HasSuffix(cookiePath, "/") || path[len(cookiePath)] == '/'
+}
+
+// defaultPath returns the default path of a cookie without a Path attribute,
+// the directory of the request path, as in RFC 6265 section 5.1.4.
+func defaultPath(path string) string {
+	if path == "" || path[0] != '/' {
+		return "/"
+	}
+
+	i := strings.LastIndex(path, "/")
+
+	if i == 0 {
+		return "/"
+	}
+
+	return path[:i]
+}
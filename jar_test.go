@@ -0,0 +1,156 @@
+package binarycookies
+
+import (
+	"bytes"
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func newTestJar(t *testing.T, data []byte, now time.Time) *Jar {
+	pages, err := New(bytes.NewReader(data)).Decode()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	j := NewJar(pages)
+	j.now = func() time.Time { return now }
+
+	return j
+}
+
+func checkJarCookies(t *testing.T, j *Jar, rawurl string, expected ...string) {
+	u, err := url.Parse(rawurl)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+
+	for _, cookie := range j.Cookies(u) {
+		names = append(names, cookie.Name+"="+cookie.Value)
+	}
+
+	if !reflect.DeepEqual(names, expected) {
+		t.Fatalf("incorrect cookies for %s\n- %v\n+ %v", rawurl, expected, names)
+	}
+}
+
+func TestJarCookies(t *testing.T) {
+	j := newTestJar(t, _test1, time.Unix(1439900000, 0))
+
+	checkJarCookies(t, j, "https://urlecho.appspot.com/", "httpOnly=value", "httpOnlySecure=value", "normal=value", "secure=value")
+	checkJarCookies(t, j, "http://URLECHO.appspot.com:8080/echo", "httpOnly=value", "normal=value")
+	checkJarCookies(t, j, "https://www.urlecho.appspot.com/")
+	checkJarCookies(t, j, "ftp://urlecho.appspot.com/")
+
+	j.now = func() time.Time { return time.Unix(1439907047, 0) }
+
+	checkJarCookies(t, j, "https://urlecho.appspot.com/")
+}
+
+func TestJarCookiesDomain(t *testing.T) {
+	j := newTestJar(t, _test2, time.Unix(1364900000, 0))
+
+	// NOTES(cixtor): all the cookies have the same path, so they are sorted
+	// by creation time, then in the same order as in the file.
+
+	checkJarCookies(t, j, "http://apple.com/", "s_invisit_n2_us=3", "s_pathLength=homepage%3D1%2C", "s_vnum_n2_us=3%7C1", "dssid2=b267acef-b91e-4a5e-8f15-54be2c037b1c", "pxro=1", "s_pv=apple%20-%20index%2Ftab%20%28us%29", "s_vi=[CS]v1|28ADA9F785011356-60001602602D90C1[CE]")
+	checkJarCookies(t, j, "http://www.store.apple.com/", "s_invisit_n2_us=3", "s_pathLength=homepage%3D1%2C", "s_vnum_n2_us=3%7C1", "dssid2=b267acef-b91e-4a5e-8f15-54be2c037b1c", "pxro=1", "s_pv=apple%20-%20index%2Ftab%20%28us%29", "dc=nwk", "s_vi=[CS]v1|28ADA9F785011356-60001602602D90C1[CE]", "asmetrics=%257B%2522store%2522%253A%257B%2522sid%2522%253A%2522wHF2F2PHCCCX72KDY%2522%252C%2522vh%2522%253Atrue%257D%257D")
+	checkJarCookies(t, j, "http://notapple.com/")
+}
+
+func TestJarSetCookies(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	j := NewJar(nil)
+	j.now = func() time.Time { return now }
+
+	u, _ := url.Parse("https://www.example.com/account/login")
+
+	j.SetCookies(u, []*http.Cookie{
+		{Name: "host", Value: "1", MaxAge: 3600},
+		{Name: "domain", Value: "2", Domain: ".example.com", Path: "/", Expires: now.Add(time.Hour)},
+		{Name: "secure", Value: "3", Path: "/", Secure: true, HttpOnly: true, SameSite: http.SameSiteLaxMode},
+		{Name: "foreign", Value: "4", Domain: "example.org"},
+		{Name: "suffix", Value: "5", Domain: "com"},
+		{Name: "expired", Value: "6", Expires: now.Add(-time.Hour)},
+	})
+
+	checkJarCookies(t, j, "https://www.example.com/account/", "host=1", "domain=2", "secure=3")
+	checkJarCookies(t, j, "http://www.example.com/account", "host=1", "domain=2")
+	checkJarCookies(t, j, "https://www.example.com/", "domain=2", "secure=3")
+	checkJarCookies(t, j, "https://example.com/account/", "domain=2")
+	checkJarCookies(t, j, "https://www.example.com/accounts/", "domain=2", "secure=3")
+
+	created := j.cookies[0].Creation
+	now = now.Add(time.Minute)
+
+	j.SetCookies(u, []*http.Cookie{
+		{Name: "host", Value: "7", MaxAge: 3600},
+		{Name: "domain", Value: "", Domain: "example.com", Path: "/", MaxAge: -1},
+	})
+
+	checkJarCookies(t, j, "https://www.example.com/account/", "host=7", "secure=3")
+
+	if !j.cookies[0].Creation.Equal(created) {
+		t.Fatalf("replaced cookie should keep the creation time\n- %s\n+ %s", created, j.cookies[0].Creation)
+	}
+
+	if j.cookies[1].Flags != FlagSecure|FlagHttpOnly {
+		t.Fatalf("incorrect flags %s", j.cookies[1].Flags)
+	}
+}
+
+func TestJarSave(t *testing.T) {
+	now := time.Unix(1439900000, 0)
+	j := newTestJar(t, _test1, now)
+
+	u, _ := url.Parse("https://urlecho.appspot.com/")
+
+	j.SetCookies(u, []*http.Cookie{
+		{Name: "session", Value: "1"},
+		{Name: "normal", Value: "changed", Path: "/", MaxAge: 60},
+	})
+
+	var buf bytes.Buffer
+
+	if err := j.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	saved := newTestJar(t, buf.Bytes(), now)
+
+	checkJarCookies(t, saved, "https://urlecho.appspot.com/", "httpOnly=value", "httpOnlySecure=value", "normal=changed", "secure=value")
+}
+
+func TestNewJarCopy(t *testing.T) {
+	data := append([]byte{}, _test1...)
+
+	pages, err := DecodeBytes(data)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	j := NewJar(pages)
+
+	// NOTES(cixtor): the cookie strings alias the input, overwriting it must
+	// not modify the cookies in the jar.
+	for i := range data {
+		data[i] = 'x'
+	}
+
+	expected, err := New(bytes.NewReader(_test1)).Decode()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(j.cookies) == 0 || !reflect.DeepEqual(j.cookies, NewJar(expected).cookies) {
+		t.Fatal("cookies in the jar should not alias the decoded pages")
+	}
+}
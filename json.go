@@ -0,0 +1,173 @@
+package binarycookies
+
+import (
+	"encoding/json"
+	"time"
+	"unicode/utf8"
+)
+
+// cookieJSON is the JSON representation of a Cookie.
+type cookieJSON struct {
+	Domain     string    `json:"domain"`
+	DomainRaw  []byte    `json:"domainRaw,omitempty"`
+	Name       string    `json:"name"`
+	NameRaw    []byte    `json:"nameRaw,omitempty"`
+	Path       string    `json:"path"`
+	PathRaw    []byte    `json:"pathRaw,omitempty"`
+	Value      string    `json:"value"`
+	ValueRaw   []byte    `json:"valueRaw,omitempty"`
+	Comment    string    `json:"comment,omitempty"`
+	CommentRaw []byte    `json:"commentRaw,omitempty"`
+	Expires    time.Time `json:"expires"`
+	Creation   time.Time `json:"creation"`
+	Secure     bool      `json:"secure"`
+	HttpOnly   bool      `json:"httpOnly"`
+	HostOnly   bool      `json:"hostOnly,omitempty"`
+	OtherFlags Flags     `json:"otherFlags,omitempty"`
+	Orphaned   bool      `json:"orphaned,omitempty"`
+}
+
+// MarshalJSON returns the JSON representation of the cookie, an object with
+// the following fields:
+//
+//	domain, name, path, value, comment: the cookie strings. Strings that are
+//	not valid UTF-8 are empty, and the bytes are in domainRaw, nameRaw,
+//	pathRaw, valueRaw and commentRaw, encoded in base64. The comment is
+//	omitted if empty.
+//	expires, creation: the timestamps in RFC 3339 format, in UTC.
+//	secure, httpOnly: the flags of the cookie.
+//	hostOnly: the result of Cookie.HostOnly, omitted if false. It is derived
+//	from the domain and ignored by UnmarshalJSON.
+//	otherFlags: the bits of Cookie.Flags not represented by the fields above,
+//	omitted if zero.
+//	orphaned: true if the cookie was recovered from the unused bytes of a page.
+//
+// The sizes, offsets and positions in the file are not included, as well as
+// the unknown fields and the unused bytes before the cookie, so encoding a
+// cookie decoded from JSON produces the same strings, timestamps and flags,
+// but not necessarily the same bytes as the original file.
+func (c Cookie) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.toJSON())
+}
+
+// UnmarshalJSON sets the cookie from the JSON representation described in
+// MarshalJSON. The Secure and HttpOnly fields and Flags are set together.
+func (c *Cookie) UnmarshalJSON(data []byte) error {
+	var v cookieJSON
+
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	*c = v.toCookie()
+
+	return nil
+}
+
+// toJSON returns the JSON representation of the cookie.
+func (c Cookie) toJSON() cookieJSON {
+	v := cookieJSON{
+		Expires:    c.Expires.UTC(),
+		Creation:   c.Creation.UTC(),
+		Secure:     c.Secure,
+		HttpOnly:   c.HttpOnly,
+		HostOnly:   c.HostOnly(),
+		OtherFlags: c.Flags &^ (FlagSecure | FlagHttpOnly),
+		Orphaned:   c.Orphaned,
+	}
+
+	v.Domain, v.DomainRaw = jsonString(c.Domain)
+	v.Name, v.NameRaw = jsonString(c.Name)
+	v.Path, v.PathRaw = jsonString(c.Path)
+	v.Value, v.ValueRaw = jsonString(c.Value)
+	v.Comment, v.CommentRaw = jsonString(c.Comment)
+
+	return v
+}
+
+// toCookie returns the cookie described by the JSON representation.
+func (v cookieJSON) toCookie() Cookie {
+	c := Cookie{
+		Domain:   cookieBytes(v.Domain, v.DomainRaw),
+		Name:     cookieBytes(v.Name, v.NameRaw),
+		Path:     cookieBytes(v.Path, v.PathRaw),
+		Value:    cookieBytes(v.Value, v.ValueRaw),
+		Expires:  v.Expires,
+		Creation: v.Creation,
+		Secure:   v.Secure,
+		HttpOnly: v.HttpOnly,
+		Flags:    v.OtherFlags,
+		Orphaned: v.Orphaned,
+	}
+
+	if v.Comment != "" || v.CommentRaw != nil {
+		c.Comment = cookieBytes(v.Comment, v.CommentRaw)
+	}
+
+	if v.Secure {
+		c.Flags |= FlagSecure
+	}
+
+	if v.HttpOnly {
+		c.Flags |= FlagHttpOnly
+	}
+
+	return c
+}
+
+// jsonString returns data as a string if it is valid UTF-8, otherwise the
+// string is empty and the bytes are returned as they are.
+func jsonString(data []byte) (string, []byte) {
+	if utf8.Valid(data) {
+		return string(data), nil
+	}
+
+	return "", data
+}
+
+// cookieBytes returns the raw bytes of a cookie string if not nil, otherwise
+// the bytes of the string.
+func cookieBytes(s string, raw []byte) []byte {
+	if raw != nil {
+		return raw
+	}
+
+	return []byte(s)
+}
+
+// carvedCookieJSON is the JSON representation of a CarvedCookie.
+type carvedCookieJSON struct {
+	cookieJSON
+	Offset int64 `json:"offset"`
+	Page   int64 `json:"page"`
+	File   int64 `json:"file"`
+}
+
+// MarshalJSON returns the JSON representation of the cookie described in
+// Cookie.MarshalJSON, with the position of the cookie in the stream in offset,
+// and the position of its page and file in page and file, or -1 if unknown.
+func (c CarvedCookie) MarshalJSON() ([]byte, error) {
+	return json.Marshal(carvedCookieJSON{
+		cookieJSON: c.Cookie.toJSON(),
+		Offset:     c.Start,
+		Page:       c.Page,
+		File:       c.File,
+	})
+}
+
+// UnmarshalJSON sets the cookie from the JSON representation described in
+// MarshalJSON.
+func (c *CarvedCookie) UnmarshalJSON(data []byte) error {
+	var v carvedCookieJSON
+
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	cookie := v.toCookie()
+	cookie.Start = v.Offset
+
+	*c = CarvedCookie{Cookie: cookie, Page: v.Page, File: v.File}
+
+	return nil
+}
@@ -0,0 +1,110 @@
+package binarycookies
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCookieMarshalJSON(t *testing.T) {
+	cookie := Cookie{
+		Flags:    FlagSecure | FlagHttpOnly | 0x108,
+		Secure:   true,
+		HttpOnly: true,
+		Domain:   []byte("www.example.com"),
+		Name:     []byte("session"),
+		Path:     []byte("/"),
+		Value:    []byte{0xff, 0xfe, 0x0},
+		Expires:  time.Date(2030, 1, 2, 3, 4, 5, 500000000, time.UTC),
+		Creation: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		Size:     123,
+	}
+
+	expected := `{"domain":"www.example.com","name":"session","path":"/","value":"","valueRaw":"//4A",` +
+		`"expires":"2030-01-02T03:04:05.5Z","creation":"2020-01-02T03:04:05Z","secure":true,"httpOnly":true,` +
+		`"hostOnly":true,"otherFlags":264}`
+
+	data, err := json.Marshal(cookie)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(data) != expected {
+		t.Fatalf("incorrect JSON\n- %s\n+ %s", expected, data)
+	}
+
+	var decoded Cookie
+
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	cookie.Size = 0
+
+	if !reflect.DeepEqual(decoded, cookie) {
+		t.Fatalf("incorrect cookie\n- %#v\n+ %#v", cookie, decoded)
+	}
+}
+
+func TestCookieJSONRoundTrip(t *testing.T) {
+	for _, data := range [][]byte{_test1, _test2} {
+		dec := New(bytes.NewReader(data))
+		pages, err := dec.Decode()
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		out, err := json.Marshal(pages)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var decoded []Page
+
+		if err := json.Unmarshal(out, &decoded); err != nil {
+			t.Fatal(err)
+		}
+
+		var buf bytes.Buffer
+
+		enc := NewEncoder(&buf)
+		enc.SetTrailer(dec.Trailer())
+
+		if err := enc.Encode(decoded); err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(buf.Bytes(), data) {
+			t.Fatalf("incorrect encoding after JSON\n- %#v\n+ %#v", data, buf.Bytes())
+		}
+	}
+}
+
+func TestCarvedCookieJSON(t *testing.T) {
+	cookie := CarvedCookie{
+		Cookie: Cookie{Domain: []byte("example.com"), Name: []byte("a"), Path: []byte("/"), Start: 1234},
+		Page:   1200,
+		File:   -1,
+	}
+
+	data, err := json.Marshal(cookie)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded CarvedCookie
+
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	if decoded.Start != 1234 || decoded.Page != 1200 || decoded.File != -1 || string(decoded.Domain) != "example.com" {
+		t.Fatalf("incorrect carved cookie %s", data)
+	}
+}
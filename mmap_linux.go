@@ -0,0 +1,70 @@
+package binarycookies
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// MappedFile is a binary cookies file mapped into memory, to be decoded with
+// NewDecoderBytes without reading the entire file first.
+type MappedFile struct {
+	data []byte
+}
+
+// OpenMapped maps the named file into memory as read-only.
+//
+// The cookies decoded from the mapped bytes alias the mapping, so they must
+// not be used after Close. Copy the fields that need to outlive the file.
+func OpenMapped(name string) (*MappedFile, error) {
+	file, err := os.Open(name)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	info, err := file.Stat()
+
+	if err != nil {
+		return nil, err
+	}
+
+	size := info.Size()
+
+	// NOTES(cixtor): empty files cannot be mapped, there is nothing to decode
+	// anyway and the decoder reports the missing signature.
+	if size == 0 {
+		return &MappedFile{}, nil
+	}
+
+	if int64(int(size)) != size {
+		return nil, fmt.Errorf("mmap %s; file too large %d", name, size)
+	}
+
+	data, err := syscall.Mmap(int(file.Fd()), 0, int(size), syscall.PROT_READ, syscall.MAP_SHARED)
+
+	if err != nil {
+		return nil, fmt.Errorf("mmap %s; %w", name, err)
+	}
+
+	return &MappedFile{data: data}, nil
+}
+
+// Bytes returns the content of the file.
+func (m *MappedFile) Bytes() []byte {
+	return m.data
+}
+
+// Close unmaps the file.
+func (m *MappedFile) Close() error {
+	if m.data == nil {
+		return nil
+	}
+
+	data := m.data
+	m.data = nil
+
+	return syscall.Munmap(data)
+}
@@ -0,0 +1,35 @@
+//go:build !linux
+// +build !linux
+
+package binarycookies
+
+import "os"
+
+// MappedFile is a binary cookies file loaded into memory, to be decoded with
+// NewDecoderBytes. Memory mapping is only supported on Linux, other systems
+// read the entire file instead.
+type MappedFile struct {
+	data []byte
+}
+
+// OpenMapped reads the named file into memory.
+func OpenMapped(name string) (*MappedFile, error) {
+	data, err := os.ReadFile(name)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &MappedFile{data: data}, nil
+}
+
+// Bytes returns the content of the file.
+func (m *MappedFile) Bytes() []byte {
+	return m.data
+}
+
+// Close releases the content of the file.
+func (m *MappedFile) Close() error {
+	m.data = nil
+	return nil
+}
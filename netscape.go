@@ -0,0 +1,247 @@
+package binarycookies
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// httpOnlyPrefix marks the domain of HttpOnly cookies in a Netscape cookie
+// file, which is otherwise a comment.
+const httpOnlyPrefix = "#HttpOnly_"
+
+// ReadNetscape reads a Netscape cookie file, also known as cookies.txt, used by
+// curl, wget and many browser extensions, and returns the cookies grouped in
+// one page per domain, in the order the domains appear in the file.
+//
+// Every line has seven fields separated by tabs: domain, include subdomains,
+// path, secure, expiration time as a Unix timestamp, name and value, which may
+// be missing if empty. Empty lines and lines starting with a "#" are ignored,
+// except for domains with the "#HttpOnly_" prefix, which are HttpOnly cookies.
+//
+// The domain of cookies including subdomains always starts with a dot and the
+// domain of the rest never does. The file has no creation time, so Creation is
+// the time the file is read, or the expiration time if it is earlier.
+//
+// Session cookies, with an expiration time of zero, are skipped, the same as
+// in Jar.Pages. Binary cookies files only store persistent cookies, and a zero
+// Expires time is encoded as the year 1, which means the cookie has expired.
+func ReadNetscape(r io.Reader) ([]Page, error) {
+	return readNetscape(r, time.Now())
+}
+
+// readNetscape reads a Netscape cookie file as if it was read at the given time.
+func readNetscape(r io.Reader, now time.Time) ([]Page, error) {
+	var pages []Page
+
+	index := map[string]int{}
+	rd := bufio.NewReader(r)
+
+	for n := 1; ; n++ {
+		line, err := rd.ReadString('\n')
+
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+
+		if line != "" {
+			cookie, ok, perr := parseNetscapeLine(strings.TrimRight(line, "\r\n"), now)
+
+			if perr != nil {
+				return nil, fmt.Errorf("line %d: %w", n, perr)
+			}
+
+			if ok {
+				domain := strings.TrimPrefix(string(cookie.Domain), ".")
+
+				if _, found := index[domain]; !found {
+					index[domain] = len(pages)
+					pages = append(pages, Page{})
+				}
+
+				page := &pages[index[domain]]
+				page.Cookies = append(page.Cookies, cookie)
+				page.Length++
+			}
+		}
+
+		if err == io.EOF {
+			return pages, nil
+		}
+	}
+}
+
+// parseNetscapeLine returns the cookie in one line of a Netscape cookie file,
+// created at the given time, and false if the line is empty, a comment, or a
+// session cookie.
+func parseNetscapeLine(line string, now time.Time) (Cookie, bool, error) {
+	var cookie Cookie
+
+	if strings.HasPrefix(line, httpOnlyPrefix) {
+		line = strings.TrimPrefix(line, httpOnlyPrefix)
+		cookie.HttpOnly = true
+	} else if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+		return Cookie{}, false, nil
+	}
+
+	fields := strings.Split(line, "\t")
+
+	// NOTES(cixtor): curl accepts lines without a value, the same as if the
+	// value was empty, but rejects lines with more than seven fields, which
+	// usually means the value contains a tab.
+	if len(fields) == 6 {
+		fields = append(fields, "")
+	}
+
+	if len(fields) != 7 {
+		return Cookie{}, false, fmt.Errorf("%d fields instead of 7; %w", len(fields), ErrBadNetscape)
+	}
+
+	if fields[0] == "" || fields[5] == "" {
+		return Cookie{}, false, fmt.Errorf("empty domain or name; %w", ErrBadNetscape)
+	}
+
+	expires, err := strconv.ParseInt(fields[4], 10, 64)
+
+	if err != nil {
+		return Cookie{}, false, fmt.Errorf("expiration time %q; %w", fields[4], ErrBadNetscape)
+	}
+
+	domain := strings.TrimPrefix(fields[0], ".")
+
+	if strings.EqualFold(fields[1], "TRUE") {
+		domain = "." + domain
+	}
+
+	cookie.Domain = []byte(domain)
+	cookie.Path = []byte(fields[2])
+	cookie.Secure = strings.EqualFold(fields[3], "TRUE")
+	cookie.Name = []byte(fields[5])
+	cookie.Value = []byte(fields[6])
+
+	if expires == 0 {
+		return Cookie{}, false, nil
+	}
+
+	cookie.Expires = time.Unix(expires, 0)
+	cookie.Creation = now
+
+	if cookie.Expires.Before(now) {
+		cookie.Creation = cookie.Expires
+	}
+
+	return cookie, true, nil
+}
+
+// netscapeHeader is the first line of every Netscape cookie file, which some
+// programs require to load the file.
+const netscapeHeader = "# Netscape HTTP Cookie File\n"
+
+// netscapeEscaper replaces the characters that would break a line of a
+// Netscape cookie file into more fields or more lines.
+var netscapeEscaper = strings.NewReplacer("\t", "%09", "\n", "%0A", "\r", "%0D")
+
+// NetscapeEncoder writes cookies into an output stream in the Netscape cookie
+// file format, also known as cookies.txt, which can be loaded by curl, wget
+// and most browser extensions.
+//
+// Cookies including subdomains are the ones with a domain starting with a dot,
+// the secure field is Cookie.Secure, and HttpOnly cookies have the domain with
+// the "#HttpOnly_" prefix. Cookies with a zero Expires time are written as
+// session cookies, with an expiration time of zero.
+//
+// The format has no way to escape characters, so tabs and line breaks in the
+// strings are written as "%09", "%0A" and "%0D", the same as in a URL. These
+// sequences are not decoded by ReadNetscape, the same as curl.
+type NetscapeEncoder struct {
+	w      io.Writer
+	header bool
+}
+
+// NewNetscapeEncoder returns a new Netscape encoder that writes to w.
+func NewNetscapeEncoder(w io.Writer) *NetscapeEncoder {
+	return &NetscapeEncoder{w: w}
+}
+
+// WriteHeader writes the first line of the file, if it was not written yet.
+func (e *NetscapeEncoder) WriteHeader() error {
+	if e.header {
+		return nil
+	}
+
+	e.header = true
+
+	_, err := io.WriteString(e.w, netscapeHeader)
+
+	return err
+}
+
+// WriteCookie writes one cookie per line, after the header. Orphaned cookies
+// are written as comments, so they are kept for inspection but not loaded.
+func (e *NetscapeEncoder) WriteCookie(cookie Cookie) error {
+	if err := e.WriteHeader(); err != nil {
+		return err
+	}
+
+	var buf strings.Builder
+
+	if cookie.Orphaned {
+		buf.WriteString("# ")
+	}
+
+	if cookie.HttpOnly {
+		buf.WriteString(httpOnlyPrefix)
+	}
+
+	var expires int64
+
+	if !cookie.Expires.IsZero() {
+		expires = cookie.Expires.Unix()
+	}
+
+	fmt.Fprintf(
+		&buf,
+		"%s\t%s\t%s\t%s\t%d\t%s\t%s\n",
+		netscapeEscaper.Replace(string(cookie.Domain)),
+		netscapeBool(!cookie.HostOnly()),
+		netscapeEscaper.Replace(string(cookie.Path)),
+		netscapeBool(cookie.Secure),
+		expires,
+		netscapeEscaper.Replace(string(cookie.Name)),
+		netscapeEscaper.Replace(string(cookie.Value)),
+	)
+
+	_, err := io.WriteString(e.w, buf.String())
+
+	return err
+}
+
+// Encode writes the header and every cookie in pages.
+func (e *NetscapeEncoder) Encode(pages []Page) error {
+	if err := e.WriteHeader(); err != nil {
+		return err
+	}
+
+	for _, page := range pages {
+		for _, cookie := range page.Cookies {
+			if err := e.WriteCookie(cookie); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// netscapeBool returns the representation of a boolean in a Netscape cookie
+// file.
+func netscapeBool(b bool) string {
+	if b {
+		return "TRUE"
+	}
+
+	return "FALSE"
+}
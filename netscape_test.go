@@ -0,0 +1,219 @@
+package binarycookies
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const netscapeFile = "# Netscape HTTP Cookie File\n" +
+	"# https://curl.se/docs/http-cookies.html\n" +
+	"\n" +
+	".example.com\tTRUE\t/\tFALSE\t1700000000\tsession\tabc123\n" +
+	"#HttpOnly_example.com\tTRUE\t/account\tTRUE\t1800000000\ttoken\txyz\r\n" +
+	".example.com\tTRUE\t/\tFALSE\t0\tsession\tskipped\n" +
+	"www.example.org\tFALSE\t/\tTRUE\t1800000000\tempty\n" +
+	"#www.example.org\tFALSE\t/\tFALSE\t0\tcommented\tout\n" +
+	".example.com\tFALSE\t/\tFALSE\t1700000000\thost\tonly"
+
+func TestReadNetscape(t *testing.T) {
+	now := time.Unix(1750000000, 0)
+	pages, err := readNetscape(strings.NewReader(netscapeFile), now)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Page{
+		{
+			Length: 3,
+			Cookies: []Cookie{
+				{Domain: []byte(".example.com"), Path: []byte("/"), Name: []byte("session"), Value: []byte("abc123"), Expires: time.Unix(1700000000, 0), Creation: time.Unix(1700000000, 0)},
+				{Domain: []byte(".example.com"), Path: []byte("/account"), Name: []byte("token"), Value: []byte("xyz"), Secure: true, HttpOnly: true, Expires: time.Unix(1800000000, 0), Creation: now},
+				{Domain: []byte("example.com"), Path: []byte("/"), Name: []byte("host"), Value: []byte("only"), Expires: time.Unix(1700000000, 0), Creation: time.Unix(1700000000, 0)},
+			},
+		},
+		{
+			Length: 1,
+			Cookies: []Cookie{
+				{Domain: []byte("www.example.org"), Path: []byte("/"), Name: []byte("empty"), Value: []byte(""), Secure: true, Expires: time.Unix(1800000000, 0), Creation: now},
+			},
+		},
+	}
+
+	if !reflect.DeepEqual(pages, expected) {
+		t.Fatalf("incorrect pages\n- %v\n+ %v", expected, pages)
+	}
+
+	var buf bytes.Buffer
+
+	if err := NewEncoder(&buf).Encode(pages); err != nil {
+		t.Fatal(err)
+	}
+
+	decoded, err := DecodeBytes(buf.Bytes())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, page := range decoded {
+		for j, cookie := range page.Cookies {
+			if cookie.String() != expected[i].Cookies[j].String() {
+				t.Fatalf("incorrect encoded cookie #%d in page #%d\n- %s\n+ %s", j, i, expected[i].Cookies[j], cookie)
+			}
+
+			if !cookie.Expires.Equal(expected[i].Cookies[j].Expires) {
+				t.Fatalf("incorrect encoded expiration #%d in page #%d\n- %s\n+ %s", j, i, expected[i].Cookies[j].Expires, cookie.Expires)
+			}
+		}
+	}
+}
+
+func TestReadNetscapeErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"Fields", "example.com\tFALSE\t/\tFALSE\t0\n"},
+		{"Tab", "example.com\tFALSE\t/\tFALSE\t0\tname\tvalue\twith tab\n"},
+		{"Expires", "example.com\tFALSE\t/\tFALSE\tnever\tname\tvalue\n"},
+		{"Name", "example.com\tFALSE\t/\tFALSE\t0\t\tvalue\n"},
+	}
+
+	for _, test := range tests {
+		_, err := ReadNetscape(strings.NewReader("# Netscape HTTP Cookie File\n" + test.data))
+
+		if !errors.Is(err, ErrBadNetscape) {
+			t.Fatalf("%s: incorrect error\n- %s\n+ %v", test.name, ErrBadNetscape, err)
+		}
+
+		if !strings.HasPrefix(err.Error(), "line 2: ") {
+			t.Fatalf("%s: error should include the line number, got %s", test.name, err)
+		}
+	}
+}
+
+const netscapeTest1 = "# Netscape HTTP Cookie File\n" +
+	"#HttpOnly_urlecho.appspot.com\tFALSE\t/\tFALSE\t1439907047\thttpOnly\tvalue\n" +
+	"#HttpOnly_urlecho.appspot.com\tFALSE\t/\tTRUE\t1439907047\thttpOnlySecure\tvalue\n" +
+	"urlecho.appspot.com\tFALSE\t/\tFALSE\t1439907047\tnormal\tvalue\n" +
+	"urlecho.appspot.com\tFALSE\t/\tTRUE\t1439907047\tsecure\tvalue\n"
+
+func TestNetscapeEncoder(t *testing.T) {
+	pages, err := New(bytes.NewReader(_test1)).Decode()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+
+	if err := NewNetscapeEncoder(&buf).Encode(pages); err != nil {
+		t.Fatal(err)
+	}
+
+	if buf.String() != netscapeTest1 {
+		t.Fatalf("incorrect Netscape file\n- %q\n+ %q", netscapeTest1, buf.String())
+	}
+}
+
+func TestNetscapeEncoderEscape(t *testing.T) {
+	cookies := []Cookie{
+		{Domain: []byte(".example.com"), Path: []byte("/"), Name: []byte("tab"), Value: []byte("a\tb"), Expires: time.Unix(1700000000, 0)},
+		{Domain: []byte("example.com"), Path: []byte("/\r\n"), Name: []byte("session"), Value: []byte("x"), Secure: true, HttpOnly: true},
+		{Domain: []byte("example.com"), Path: []byte("/"), Name: []byte("old"), Value: []byte("y"), Orphaned: true},
+	}
+
+	expected := "# Netscape HTTP Cookie File\n" +
+		".example.com\tTRUE\t/\tFALSE\t1700000000\ttab\ta%09b\n" +
+		"#HttpOnly_example.com\tFALSE\t/%0D%0A\tTRUE\t0\tsession\tx\n" +
+		"# example.com\tFALSE\t/\tFALSE\t0\told\ty\n"
+
+	var buf bytes.Buffer
+
+	enc := NewNetscapeEncoder(&buf)
+
+	for _, cookie := range cookies {
+		if err := enc.WriteCookie(cookie); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if buf.String() != expected {
+		t.Fatalf("incorrect Netscape file\n- %q\n+ %q", expected, buf.String())
+	}
+
+	// NOTES(cixtor): curl reads the domain, the include subdomains flag, the
+	// path, the secure flag, the expiration time, the name and the value from
+	// every line with exactly seven fields separated by tabs, and ignores the
+	// comments except for the ones with the "#HttpOnly_" prefix.
+	lines := strings.Split(strings.TrimSuffix(expected, "\n"), "\n")
+
+	for i, line := range lines[1:3] {
+		fields := strings.Split(strings.TrimPrefix(line, httpOnlyPrefix), "\t")
+
+		if len(fields) != 7 {
+			t.Fatalf("line %d should have 7 fields for curl, got %d", i+2, len(fields))
+		}
+
+		if tailmatch := fields[1] == "TRUE"; tailmatch != strings.HasPrefix(fields[0], ".") {
+			t.Fatalf("line %d include subdomains should match the leading dot", i+2)
+		}
+
+		if httpOnly := strings.HasPrefix(line, httpOnlyPrefix); httpOnly != cookies[i].HttpOnly {
+			t.Fatalf("line %d HttpOnly prefix should match the cookie", i+2)
+		}
+	}
+
+	pages, err := ReadNetscape(strings.NewReader(expected))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(pages) != 1 || len(pages[0].Cookies) != 1 {
+		t.Fatalf("orphaned and session cookies should be ignored when reading the file, got %v", pages)
+	}
+}
+
+func TestNetscapeRoundTrip(t *testing.T) {
+	pages, err := New(bytes.NewReader(_test2)).Decode()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+
+	if err := NewNetscapeEncoder(&buf).Encode(pages); err != nil {
+		t.Fatal(err)
+	}
+
+	imported, err := ReadNetscape(&buf)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var expected, cookies []string
+
+	for _, page := range pages {
+		for _, cookie := range page.Cookies {
+			expected = append(expected, cookie.String())
+		}
+	}
+
+	for _, page := range imported {
+		for _, cookie := range page.Cookies {
+			cookies = append(cookies, cookie.String())
+		}
+	}
+
+	if !reflect.DeepEqual(cookies, expected) {
+		t.Fatalf("incorrect cookies\n- %v\n+ %v", expected, cookies)
+	}
+}
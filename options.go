@@ -0,0 +1,116 @@
+package binarycookies
+
+import (
+	"fmt"
+	"io"
+)
+
+// DecoderOptions configures the limits and the validation rules used by the
+// decoder. Services decoding files from untrusted sources should set limits
+// to bound the memory and the time spent on every file. The zero value of a
+// limit means the default, which is no limit except for MaxCookieSize.
+type DecoderOptions struct {
+	// MaxFileSize is the maximum number of bytes read from the file.
+	MaxFileSize int64
+
+	// MaxPages is the maximum number of pages in the file.
+	MaxPages int
+
+	// MaxCookiesPerPage is the maximum number of cookies in a single page.
+	MaxCookiesPerPage int
+
+	// MaxCookieSize is the maximum number of bytes used by the strings of a
+	// single cookie. The default is 4096 bytes, per RFC 2965.
+	MaxCookieSize int
+
+	// Workers is the maximum number of pages decoded at the same time by
+	// Decode. The pages are read in order, then decoded by a pool of workers,
+	// and returned in the same order as in the file. Values lower than 2
+	// decode one page at a time. Next always decodes one page at a time.
+	Workers int
+
+	// Lenient accepts pages and cookies with unexpected page tags, page end
+	// markers and cookie end headers, which some tools fail to write, and
+	// decodes invalid timestamps as the zero time instead of returning
+	// ErrBadTime. The offsets and sizes are validated nonetheless.
+	Lenient bool
+
+	// DisallowInvalidChecksum returns ErrChecksum when the checksum stored in
+	// the file does not match the checksum of the pages.
+	DisallowInvalidChecksum bool
+
+	// RecoverCorrupted skips the pages and cookies that cannot be decoded
+	// instead of failing. See BinaryCookies.RecoverCorrupted.
+	RecoverCorrupted bool
+
+	// RecoverSlack looks for old cookies in the bytes between cookies and at
+	// the end of every page, which are not referenced by the cookie offsets.
+	// The cookies found are stored in Page.Orphans, and returned by Next after
+	// the cookies of the page, with Cookie.Orphaned set.
+	RecoverSlack bool
+}
+
+// NewDecoder returns an instance of the Binary Cookies class that decodes the
+// file using the given options. A nil value uses the default options, which
+// is the same as calling New.
+func NewDecoder(reader io.Reader, opts *DecoderOptions) *BinaryCookies {
+	b := New(reader)
+
+	if opts == nil {
+		return b
+	}
+
+	b.opts = *opts
+
+	if opts.MaxFileSize > 0 {
+		b.file = &limitReader{r: reader, n: opts.MaxFileSize}
+	}
+
+	return b
+}
+
+// maxCookieSize returns the maximum number of bytes used by a single cookie.
+func (o DecoderOptions) maxCookieSize() uint32 {
+	if o.MaxCookieSize > 0 {
+		return uint32(o.MaxCookieSize)
+	}
+
+	return maxCookieSize
+}
+
+// checkLimit returns ErrLimitExceeded if n is greater than a non-zero limit.
+func checkLimit(n int64, limit int64) error {
+	if limit > 0 && n > limit {
+		return fmt.Errorf("%d > %d; %w", n, limit, ErrLimitExceeded)
+	}
+
+	return nil
+}
+
+// limitReader reads from r until n bytes are read, then fails with
+// ErrLimitExceeded if there are more bytes to read.
+type limitReader struct {
+	r io.Reader
+	n int64
+}
+
+func (l *limitReader) Read(data []byte) (int, error) {
+	if l.n <= 0 {
+		// NOTES(cixtor): a file with exactly the maximum size is valid, the
+		// limit is only exceeded if there is at least one more byte.
+		if n, err := l.r.Read(make([]byte, 1)); n == 0 {
+			return 0, err
+		}
+
+		return 0, fmt.Errorf("file size; %w", ErrLimitExceeded)
+	}
+
+	if int64(len(data)) > l.n {
+		data = data[:l.n]
+	}
+
+	n, err := l.r.Read(data)
+	l.n -= int64(n)
+
+	return n, err
+}
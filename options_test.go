@@ -0,0 +1,121 @@
+package binarycookies
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestNewDecoderDefault(t *testing.T) {
+	expected, err := New(bytes.NewReader(_test1)).Decode()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pages, err := NewDecoder(bytes.NewReader(_test1), nil).Decode()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(pages, expected) {
+		t.Fatalf("incorrect pages with default options\n- %v\n+ %v", expected, pages)
+	}
+}
+
+func TestDecoderOptionsLimits(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   DecoderOptions
+		target error
+	}{
+		{"MaxPages", DecoderOptions{MaxPages: 10}, ErrLimitExceeded},
+		{"MaxCookiesPerPage", DecoderOptions{MaxCookiesPerPage: 3}, ErrLimitExceeded},
+		{"MaxCookieSize", DecoderOptions{MaxCookieSize: 32}, ErrCookieTooLarge},
+		{"MaxFileSize", DecoderOptions{MaxFileSize: int64(len(_test1) - 1)}, ErrLimitExceeded},
+		{"MaxFileSizePages", DecoderOptions{MaxFileSize: 100}, ErrLimitExceeded},
+	}
+
+	for _, test := range tests {
+		_, err := NewDecoder(bytes.NewReader(_test1), &test.opts).Decode()
+
+		if !errors.Is(err, test.target) {
+			t.Fatalf("%s: incorrect error\n- %s\n+ %v", test.name, test.target, err)
+		}
+	}
+
+	opts := DecoderOptions{
+		MaxFileSize:       int64(len(_test1)),
+		MaxPages:          11,
+		MaxCookiesPerPage: 4,
+		MaxCookieSize:     maxCookieSize,
+	}
+
+	if _, err := NewDecoder(bytes.NewReader(_test1), &opts).Decode(); err != nil {
+		t.Fatalf("file within the limits should be decoded, got %s", err)
+	}
+}
+
+func TestDecoderOptionsLimitPosition(t *testing.T) {
+	var e *DecodeError
+
+	opts := DecoderOptions{MaxCookiesPerPage: 3}
+	_, err := NewDecoder(bytes.NewReader(_test1), &opts).Decode()
+
+	if !errors.As(err, &e) || e.Field != "cookie count" || e.Page != 1 || e.Offset != 68 {
+		t.Fatalf("incorrect error position\n- \"cookie count\" page #1 at offset 68\n+ %v", err)
+	}
+}
+
+func TestDecoderOptionsLenient(t *testing.T) {
+	data := corrupt(_test1, 185+36, 0xff)
+	data = corrupt(data, 466, 0xff)
+	data = corrupt(data, 64+24, 0xff)
+
+	if _, err := New(bytes.NewReader(data)).Decode(); err == nil {
+		t.Fatal("corrupted markers should return an error")
+	}
+
+	expected, err := New(bytes.NewReader(_test1)).Decode()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pages, err := NewDecoder(bytes.NewReader(data), &DecoderOptions{Lenient: true}).Decode()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(pages, expected) {
+		t.Fatalf("incorrect pages in lenient mode\n- %v\n+ %v", expected, pages)
+	}
+}
+
+func TestDecoderOptionsLenientTime(t *testing.T) {
+	// NOTES(cixtor): the expiration time of the second cookie in page #1 is a
+	// NaN, the cookie is kept with a zero expiration time.
+	data := corrupt(_test1, 185+40, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0xf8, 0x7f)
+
+	pages, err := NewDecoder(bytes.NewReader(data), &DecoderOptions{Lenient: true}).Decode()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cookie := pages[1].Cookies[1]; !cookie.Expires.IsZero() || cookie.Creation.IsZero() {
+		t.Fatalf("incorrect timestamps in lenient mode\n+ %s %s", cookie.Expires, cookie.Creation)
+	}
+}
+
+func TestDecoderOptionsChecksum(t *testing.T) {
+	data := corrupt(_test2, len(_test2)-8, 0xff)
+	opts := DecoderOptions{DisallowInvalidChecksum: true}
+
+	if _, err := NewDecoder(bytes.NewReader(data), &opts).Decode(); !errors.Is(err, ErrChecksum) {
+		t.Fatalf("invalid checksum should return %s, got %v", ErrChecksum, err)
+	}
+}
@@ -0,0 +1,114 @@
+package binarycookies
+
+import (
+	"errors"
+	"sync"
+)
+
+// pageJob is one page read into memory and waiting to be decoded.
+type pageJob struct {
+	index int
+	start int64
+	size  uint32
+	data  []byte
+	err   error
+}
+
+// readPagesParallel reads every page into memory, one after the other, and
+// decodes them using the given number of workers. The pages are stored in the
+// same order as in the file, and if more than one page fails, the error of the
+// first one is returned, the same as if the pages were decoded in order.
+func (b *BinaryCookies) readPagesParallel(workers int) error {
+	var wg sync.WaitGroup
+
+	// NOTES(cixtor): the page size table was read successfully, so the number
+	// of pages is bounded by the size of the file and is safe to allocate.
+	pages := make([]Page, len(b.page))
+	errs := make([][]error, len(b.page))
+	jobs := make(chan pageJob)
+	opts := b.opts
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for job := range jobs {
+				pages[job.index] = decodePageJob(job, opts, &errs[job.index])
+			}
+		}()
+	}
+
+	for i, size := range b.page {
+		start := b.offset
+
+		b.paging = true
+		b.pageStart = start
+		b.pageIndex = i
+
+		data, err := b.readSlack("page", int64(size))
+
+		b.paging = false
+		b.pageIndex = -1
+
+		// NOTES(cixtor): a truncated page is decoded anyway, the same as in
+		// readPage, to report the field that could not be read.
+		jobs <- pageJob{index: i, start: start, size: size, data: data, err: err}
+
+		if err != nil && !opts.RecoverCorrupted {
+			break
+		}
+	}
+
+	close(jobs)
+	wg.Wait()
+
+	for _, list := range errs {
+		for _, err := range list {
+			if !opts.RecoverCorrupted {
+				return err
+			}
+
+			b.fail(err)
+		}
+	}
+
+	b.pages = pages
+
+	return nil
+}
+
+// decodePageJob decodes one page read into memory. The errors are added to the
+// list in the same order as readPage finds them, and in recovery mode, the
+// cookies that cannot be decoded are skipped.
+func decodePageJob(job pageJob, opts DecoderOptions, errs *[]error) Page {
+	var fail func(error)
+
+	if opts.RecoverCorrupted {
+		fail = func(err error) { *errs = append(*errs, err) }
+	}
+
+	if job.err != nil {
+		if fail != nil {
+			fail(job.err)
+		} else if !errors.Is(job.err, ErrTruncated) {
+			*errs = append(*errs, job.err)
+			return Page{}
+		}
+	}
+
+	page, err := readPageAt(byteSection(job.data), 0, int64(len(job.data)), job.start, job.index, opts, fail)
+
+	if err != nil {
+		*errs = append(*errs, err)
+	} else if job.err != nil && fail == nil {
+		*errs = append(*errs, job.err)
+	}
+
+	page.Start = job.start
+	page.Size = job.size
+	page.End = job.start + int64(job.size)
+
+	return page
+}
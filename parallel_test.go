@@ -0,0 +1,192 @@
+package binarycookies
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// syntheticFile returns a binary cookies file with the given number of pages
+// and three cookies per page.
+func syntheticFile(tb testing.TB, n int) []byte {
+	var buf bytes.Buffer
+
+	pages := make([]Page, n)
+	expires := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for i := range pages {
+		for j := 0; j < 3; j++ {
+			pages[i].Cookies = append(pages[i].Cookies, Cookie{
+				Domain:  []byte(fmt.Sprintf(".example%d.com", i)),
+				Name:    []byte(fmt.Sprintf("cookie%d", j)),
+				Path:    []byte("/"),
+				Value:   []byte(fmt.Sprintf("value%d-%d", i, j)),
+				Secure:  j == 1,
+				Expires: expires,
+			})
+		}
+	}
+
+	if err := NewEncoder(&buf).Encode(pages); err != nil {
+		tb.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestDecodeParallel(t *testing.T) {
+	for _, data := range [][]byte{_test1, _test2, syntheticFile(t, 3000)} {
+		expected, err := New(bytes.NewReader(data)).Decode()
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, workers := range []int{2, 4, 16} {
+			opts := DecoderOptions{Workers: workers, DisallowInvalidChecksum: true}
+			cook := NewDecoder(bytes.NewReader(data), &opts)
+
+			pages, err := cook.Decode()
+
+			if err != nil {
+				t.Fatalf("%d workers: %s", workers, err)
+			}
+
+			if !reflect.DeepEqual(pages, expected) {
+				t.Fatalf("%d workers: incorrect pages", workers)
+			}
+
+			if !cook.ChecksumValid() {
+				t.Fatalf("%d workers: incorrect checksum %#08x", workers, cook.Checksum())
+			}
+		}
+	}
+}
+
+func TestDecodeParallelFirstError(t *testing.T) {
+	// NOTES(cixtor): corrupt the page tag of page #2 and the end header of the
+	// second cookie in page #1, the error of page #1 must be returned.
+	data := corrupt(_test1, 466, 0xff)
+	data = corrupt(data, 185+36, 0xff)
+
+	opts := DecoderOptions{Workers: 4}
+
+	_, err := NewDecoder(bytes.NewReader(data), &opts).Decode()
+
+	var e *DecodeError
+
+	if !errors.As(err, &e) || !errors.Is(err, ErrBadEndHeader) || e.Page != 1 || e.Offset != 221 {
+		t.Fatalf("incorrect error\n- end header at offset 221 page #1 cookie #1\n+ %v", err)
+	}
+
+	if _, err := NewDecoder(bytes.NewReader(_test1[:500]), &opts).Decode(); !errors.Is(err, ErrTruncated) {
+		t.Fatalf("truncated file should return %s, got %v", ErrTruncated, err)
+	}
+}
+
+func TestDecodeParallelRecoverCorrupted(t *testing.T) {
+	data := corrupt(_test1, 466, 0xff)
+	data = corrupt(data, 185+36, 0xff)
+
+	cook := New(bytes.NewReader(data))
+	cook.RecoverCorrupted()
+
+	expected, err := cook.Decode()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts := DecoderOptions{Workers: 4, RecoverCorrupted: true}
+	dec := NewDecoder(bytes.NewReader(data), &opts)
+
+	pages, err := dec.Decode()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(pages, expected) {
+		t.Fatalf("incorrect recovered pages\n- %v\n+ %v", expected, pages)
+	}
+
+	if !reflect.DeepEqual(dec.Errors(), cook.Errors()) {
+		t.Fatalf("incorrect errors\n- %v\n+ %v", cook.Errors(), dec.Errors())
+	}
+}
+
+func BenchmarkDecodeSequential(b *testing.B) {
+	data := syntheticFile(b, 5000)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := New(bytes.NewReader(data)).Decode(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecodeParallel(b *testing.B) {
+	data := syntheticFile(b, 5000)
+	opts := DecoderOptions{Workers: 8}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := NewDecoder(bytes.NewReader(data), &opts).Decode(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestDecodeParallelReordered(t *testing.T) {
+	data := reorderedFile(t)
+
+	r, err := NewPageReader(bytes.NewReader(data), int64(len(data)))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected, err := r.Pages()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, workers := range []int{0, 1, 2} {
+		pages, err := NewDecoder(bytes.NewReader(data), &DecoderOptions{Workers: workers}).Decode()
+
+		if err != nil {
+			t.Fatalf("%d workers: %s", workers, err)
+		}
+
+		if !reflect.DeepEqual(pages, expected) {
+			t.Fatalf("%d workers: incorrect pages\n- %#v\n+ %#v", workers, expected, pages)
+		}
+	}
+}
+
+func TestDecodeParallelTruncated(t *testing.T) {
+	for _, n := range []int{301, 600, len(_test2) - 9} {
+		_, expected := New(bytes.NewReader(_test2[:n])).Decode()
+
+		if !errors.Is(expected, ErrTruncated) {
+			t.Fatalf("file truncated at %d bytes should return %s, got %v", n, ErrTruncated, expected)
+		}
+
+		for _, workers := range []int{2, 4} {
+			_, err := NewDecoder(bytes.NewReader(_test2[:n]), &DecoderOptions{Workers: workers}).Decode()
+
+			if !reflect.DeepEqual(err, expected) {
+				t.Fatalf("%d workers: incorrect error for file truncated at %d bytes\n- %v\n+ %v", workers, n, expected, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,377 @@
+package plist
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"unicode/utf16"
+)
+
+// maxObjects is the maximum number of objects decoded from a property list.
+// Objects can be referenced more than once, so a small file can expand into
+// an exponential number of objects without this limit.
+const maxObjects = 1 << 20
+
+// Decoder reads a binary property list from an input stream.
+type Decoder struct {
+	r io.Reader
+}
+
+// NewDecoder returns a new decoder that reads from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: r}
+}
+
+// Decode reads the entire stream and returns the top object of the property
+// list. Objects are random-accessed through the offset table at the end of the
+// file, so the whole property list is loaded into memory.
+func (d *Decoder) Decode() (interface{}, error) {
+	var buf bytes.Buffer
+
+	if _, err := buf.ReadFrom(d.r); err != nil {
+		return nil, err
+	}
+
+	return decode(buf.Bytes())
+}
+
+// bplist is a reader for binary property lists.
+//
+// A binary property list has four parts: the header with the signature, the
+// object table with every object encoded as a marker byte followed by the
+// object data, the offset table with the position of every object in the
+// file, and the trailer. Arrays and dictionaries refer to other objects by
+// their index in the offset table.
+type bplist struct {
+	data    []byte
+	offsets []uint64
+	refSize int
+	visited []bool
+	decoded int
+}
+
+// decode decodes a binary property list and returns its top object.
+func decode(data []byte) (interface{}, error) {
+	if len(data) < len(magic)+trailerSize {
+		return nil, fmt.Errorf("bplist too short %d", len(data))
+	}
+
+	if !bytes.Equal(data[:len(magic)], magic) {
+		return nil, fmt.Errorf("bplist invalid signature %q", data[:len(magic)])
+	}
+
+	trailer := data[len(data)-trailerSize:]
+	offsetSize := int(trailer[6])
+	refSize := int(trailer[7])
+	numObjects := binary.BigEndian.Uint64(trailer[8:16])
+	topObject := binary.BigEndian.Uint64(trailer[16:24])
+	tableOffset := binary.BigEndian.Uint64(trailer[24:32])
+	tableEnd := uint64(len(data) - trailerSize)
+
+	if offsetSize < 1 || offsetSize > 8 || refSize < 1 || refSize > 8 {
+		return nil, fmt.Errorf("bplist invalid integer sizes %d %d", offsetSize, refSize)
+	}
+
+	if tableOffset > tableEnd || numObjects > (tableEnd-tableOffset)/uint64(offsetSize) {
+		return nil, fmt.Errorf("bplist invalid offset table %d (%d objects)", tableOffset, numObjects)
+	}
+
+	if topObject >= numObjects {
+		return nil, fmt.Errorf("bplist invalid top object %d", topObject)
+	}
+
+	p := bplist{
+		data:    data[:tableOffset],
+		offsets: make([]uint64, numObjects),
+		refSize: refSize,
+		visited: make([]bool, numObjects),
+	}
+
+	for i := range p.offsets {
+		start := tableOffset + uint64(i*offsetSize)
+		p.offsets[i] = readUint(data[start : start+uint64(offsetSize)])
+	}
+
+	return p.object(topObject)
+}
+
+// readUint decodes a big-endian unsigned integer of up to 8 bytes.
+func readUint(data []byte) uint64 {
+	var n uint64
+
+	for _, b := range data {
+		n = n<<8 | uint64(b)
+	}
+
+	return n
+}
+
+// bytes returns n bytes of the object table starting at the given offset.
+func (p *bplist) bytes(offset uint64, n uint64) ([]byte, error) {
+	if offset > uint64(len(p.data)) || n > uint64(len(p.data))-offset {
+		return nil, fmt.Errorf("bplist object out of bounds %d+%d", offset, n)
+	}
+
+	return p.data[offset : offset+n], nil
+}
+
+// count returns the number of elements in a string, data, array or dictionary
+// and the offset of the first element. Counts greater than 14 are stored as an
+// integer object right after the marker.
+func (p *bplist) count(offset uint64, marker byte) (uint64, uint64, error) {
+	if marker&0xf != 0xf {
+		return uint64(marker & 0xf), offset + 1, nil
+	}
+
+	head, err := p.bytes(offset+1, 1)
+
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if head[0]>>4 != 0x1 || head[0]&0xf > 3 {
+		return 0, 0, fmt.Errorf("bplist invalid count marker %#02x", head[0])
+	}
+
+	size := uint64(1) << (head[0] & 0xf)
+	data, err := p.bytes(offset+2, size)
+
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return readUint(data), offset + 2 + size, nil
+}
+
+// refs reads n object references starting at the given offset.
+func (p *bplist) refs(offset uint64, n uint64) ([]uint64, error) {
+	// NOTES(cixtor): every reference uses at least one byte of the file, the
+	// check also prevents n*refSize from overflowing.
+	if n > uint64(len(p.data))/uint64(p.refSize) {
+		return nil, fmt.Errorf("bplist too many references %d", n)
+	}
+
+	data, err := p.bytes(offset, n*uint64(p.refSize))
+
+	if err != nil {
+		return nil, err
+	}
+
+	refs := make([]uint64, n)
+
+	for i := range refs {
+		refs[i] = readUint(data[i*p.refSize : (i+1)*p.refSize])
+	}
+
+	return refs, nil
+}
+
+// object decodes the object with the given index in the offset table.
+func (p *bplist) object(ref uint64) (interface{}, error) {
+	if ref >= uint64(len(p.offsets)) {
+		return nil, fmt.Errorf("bplist invalid object reference %d", ref)
+	}
+
+	// NOTES(cixtor): a malformed file can have an array or dictionary that
+	// contains itself, so objects being decoded cannot be visited again.
+	if p.visited[ref] {
+		return nil, fmt.Errorf("bplist object cycle at reference %d", ref)
+	}
+
+	if p.decoded++; p.decoded > maxObjects {
+		return nil, fmt.Errorf("bplist too many objects %d", p.decoded)
+	}
+
+	p.visited[ref] = true
+	defer func() { p.visited[ref] = false }()
+
+	offset := p.offsets[ref]
+	head, err := p.bytes(offset, 1)
+
+	if err != nil {
+		return nil, err
+	}
+
+	marker := head[0]
+
+	switch marker >> 4 {
+	case 0x0:
+		switch marker {
+		case 0x00:
+			return nil, nil
+		case 0x08:
+			return false, nil
+		case 0x09:
+			return true, nil
+		}
+
+	case 0x1:
+		if marker&0xf > 4 {
+			break
+		}
+
+		data, err := p.bytes(offset+1, uint64(1)<<(marker&0xf))
+
+		if err != nil {
+			return nil, err
+		}
+
+		// NOTES(cixtor): 16-bytes integers are used to store unsigned values
+		// greater than math.MaxInt64, the upper 8 bytes are always zero.
+		if len(data) == 16 {
+			return readUint(data[8:]), nil
+		}
+
+		return int64(readUint(data)), nil
+
+	case 0x2:
+		switch marker & 0xf {
+		case 2:
+			data, err := p.bytes(offset+1, 4)
+
+			if err != nil {
+				return nil, err
+			}
+
+			return float64(math.Float32frombits(binary.BigEndian.Uint32(data))), nil
+		case 3:
+			data, err := p.bytes(offset+1, 8)
+
+			if err != nil {
+				return nil, err
+			}
+
+			return math.Float64frombits(binary.BigEndian.Uint64(data)), nil
+		}
+
+	case 0x3:
+		if marker != 0x33 {
+			break
+		}
+
+		data, err := p.bytes(offset+1, 8)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return decodeDate(math.Float64frombits(binary.BigEndian.Uint64(data)))
+
+	case 0x4, 0x5, 0x6:
+		n, start, err := p.count(offset, marker)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if marker>>4 == 0x6 {
+			if n > uint64(len(p.data)) {
+				return nil, fmt.Errorf("bplist string too long %d", n)
+			}
+
+			n *= 2
+		}
+
+		data, err := p.bytes(start, n)
+
+		if err != nil {
+			return nil, err
+		}
+
+		switch marker >> 4 {
+		case 0x4:
+			return append([]byte{}, data...), nil
+		case 0x5:
+			return string(data), nil
+		}
+
+		units := make([]uint16, len(data)/2)
+
+		for i := range units {
+			units[i] = binary.BigEndian.Uint16(data[i*2:])
+		}
+
+		return string(utf16.Decode(units)), nil
+
+	case 0x8:
+		data, err := p.bytes(offset+1, uint64(marker&0xf)+1)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if len(data) > 8 {
+			break
+		}
+
+		return UID(readUint(data)), nil
+
+	case 0xa:
+		n, start, err := p.count(offset, marker)
+
+		if err != nil {
+			return nil, err
+		}
+
+		refs, err := p.refs(start, n)
+
+		if err != nil {
+			return nil, err
+		}
+
+		array := make([]interface{}, len(refs))
+
+		for i, ref := range refs {
+			if array[i], err = p.object(ref); err != nil {
+				return nil, err
+			}
+		}
+
+		return array, nil
+
+	case 0xd:
+		n, start, err := p.count(offset, marker)
+
+		if err != nil {
+			return nil, err
+		}
+
+		// NOTES(cixtor): the dictionary has one reference for every key and
+		// one for every value, the count is checked before doubling it.
+		if n > uint64(len(p.data))/2 {
+			return nil, fmt.Errorf("bplist too many dictionary entries %d", n)
+		}
+
+		refs, err := p.refs(start, 2*n)
+
+		if err != nil {
+			return nil, err
+		}
+
+		dict := make(map[string]interface{}, n)
+
+		for i := uint64(0); i < n; i++ {
+			key, err := p.object(refs[i])
+
+			if err != nil {
+				return nil, err
+			}
+
+			name, ok := key.(string)
+
+			if !ok {
+				return nil, fmt.Errorf("bplist invalid dictionary key %#v", key)
+			}
+
+			if dict[name], err = p.object(refs[n+i]); err != nil {
+				return nil, err
+			}
+		}
+
+		return dict, nil
+	}
+
+	return nil, fmt.Errorf("bplist unsupported object marker %#02x", marker)
+}
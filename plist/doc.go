@@ -0,0 +1,29 @@
+// Package plist implements an encoder and decoder for binary property lists,
+// also known as bplist00 files.
+//
+// A property list is a file format used by macOS and iOS applications to store
+// serialized objects. The binary variant is used by WebKit to store additional
+// information at the end of the binary cookies file, for example, the cookie
+// accept policy, and also in many of the files found next to the cookies.
+//
+// Property list objects are decoded into the following Go values:
+//
+//	null        nil
+//	boolean     bool
+//	integer     int64, or uint64 for values greater than math.MaxInt64
+//	real        float64
+//	date        time.Time
+//	data        []byte
+//	string      string
+//	uid         UID
+//	array       []interface{}
+//	dictionary  map[string]interface{}
+//
+// The encoder accepts the same values, as well as every other integer and
+// float type, and slices and maps with string keys of any supported value.
+//
+// References:
+//
+// - https://en.wikipedia.org/wiki/Property_list
+// - https://opensource.apple.com/source/CF/CF-550/CFBinaryPList.c
+package plist
@@ -0,0 +1,310 @@
+package plist
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"sort"
+	"time"
+	"unicode/utf16"
+)
+
+// Encoder writes a binary property list into an output stream.
+type Encoder struct {
+	w io.Writer
+}
+
+// NewEncoder returns a new encoder that writes to w.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w}
+}
+
+// object is one entry in the object table. Arrays and dictionaries keep the
+// index of their elements, dictionary keys first and then the values.
+type object struct {
+	value interface{}
+	refs  []int
+}
+
+// Encode writes the binary property list representation of v into the stream.
+//
+// Dictionary keys are sorted to produce the same output for the same input.
+// Integers use the smallest number of bytes able to represent the value, and
+// strings are encoded as ASCII when possible and as UTF-16 otherwise.
+func (e *Encoder) Encode(v interface{}) error {
+	var objects []object
+	var buf bytes.Buffer
+
+	if _, err := flatten(&objects, v, 0); err != nil {
+		return err
+	}
+
+	refSize := intSize(uint64(len(objects)))
+	offsets := make([]uint64, len(objects))
+
+	buf.Write(magic)
+
+	for i, obj := range objects {
+		offsets[i] = uint64(buf.Len())
+
+		writeObject(&buf, obj, refSize)
+	}
+
+	tableOffset := uint64(buf.Len())
+	offsetSize := intSize(tableOffset)
+
+	for _, offset := range offsets {
+		writeUint(&buf, offset, offsetSize)
+	}
+
+	buf.Write([]byte{0x0, 0x0, 0x0, 0x0, 0x0, 0x0})
+	buf.WriteByte(byte(offsetSize))
+	buf.WriteByte(byte(refSize))
+	writeUint(&buf, uint64(len(objects)), 8)
+	writeUint(&buf, 0, 8)
+	writeUint(&buf, tableOffset, 8)
+
+	_, err := e.w.Write(buf.Bytes())
+
+	return err
+}
+
+// flatten appends v and all the objects it contains to the object table and
+// returns the index of v. Containers are added before their elements so the
+// top object is always at index zero.
+func flatten(objects *[]object, v interface{}, depth int) (int, error) {
+	// NOTES(cixtor): a map or slice can contain itself through an interface
+	// value, the depth limit prevents the encoder from recursing forever.
+	if depth > maxDepth {
+		return 0, fmt.Errorf("bplist maximum depth exceeded %d", maxDepth)
+	}
+
+	index := len(*objects)
+
+	*objects = append(*objects, object{value: v})
+
+	switch v.(type) {
+	case nil, bool, string, []byte, time.Time, UID, float32, float64,
+		int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return index, nil
+	}
+
+	var refs []int
+
+	rv := reflect.ValueOf(v)
+
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		refs = make([]int, rv.Len())
+
+		for i := range refs {
+			ref, err := flatten(objects, rv.Index(i).Interface(), depth+1)
+
+			if err != nil {
+				return 0, err
+			}
+
+			refs[i] = ref
+		}
+
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return 0, fmt.Errorf("bplist unsupported dictionary key %s", rv.Type().Key())
+		}
+
+		keys := rv.MapKeys()
+
+		sort.Slice(keys, func(i, j int) bool {
+			return keys[i].String() < keys[j].String()
+		})
+
+		refs = make([]int, 2*len(keys))
+
+		for i, key := range keys {
+			ref, err := flatten(objects, key.String(), depth+1)
+
+			if err != nil {
+				return 0, err
+			}
+
+			refs[i] = ref
+		}
+
+		for i, key := range keys {
+			ref, err := flatten(objects, rv.MapIndex(key).Interface(), depth+1)
+
+			if err != nil {
+				return 0, err
+			}
+
+			refs[len(keys)+i] = ref
+		}
+
+	default:
+		return 0, fmt.Errorf("bplist unsupported type %T", v)
+	}
+
+	(*objects)[index].refs = refs
+
+	return index, nil
+}
+
+// writeObject writes the marker and the data of one object.
+func writeObject(buf *bytes.Buffer, obj object, refSize int) {
+	switch v := obj.value.(type) {
+	case nil:
+		buf.WriteByte(0x00)
+	case bool:
+		if v {
+			buf.WriteByte(0x09)
+		} else {
+			buf.WriteByte(0x08)
+		}
+	case int:
+		writeInt(buf, int64(v))
+	case int8:
+		writeInt(buf, int64(v))
+	case int16:
+		writeInt(buf, int64(v))
+	case int32:
+		writeInt(buf, int64(v))
+	case int64:
+		writeInt(buf, v)
+	case uint:
+		writeUnsigned(buf, uint64(v))
+	case uint8:
+		writeUnsigned(buf, uint64(v))
+	case uint16:
+		writeUnsigned(buf, uint64(v))
+	case uint32:
+		writeUnsigned(buf, uint64(v))
+	case uint64:
+		writeUnsigned(buf, v)
+	case float32:
+		buf.WriteByte(0x22)
+		writeUint(buf, uint64(math.Float32bits(v)), 4)
+	case float64:
+		buf.WriteByte(0x23)
+		writeUint(buf, math.Float64bits(v), 8)
+	case time.Time:
+		buf.WriteByte(0x33)
+		writeUint(buf, math.Float64bits(encodeDate(v)), 8)
+	case []byte:
+		writeCount(buf, 0x40, len(v))
+		buf.Write(v)
+	case string:
+		writeString(buf, v)
+	case UID:
+		size := intSize(uint64(v))
+		buf.WriteByte(0x80 | byte(size-1))
+		writeUint(buf, uint64(v), size)
+	default:
+		marker := byte(0xa0)
+		count := len(obj.refs)
+
+		if reflect.ValueOf(v).Kind() == reflect.Map {
+			marker = 0xd0
+			count /= 2
+		}
+
+		writeCount(buf, marker, count)
+
+		for _, ref := range obj.refs {
+			writeUint(buf, uint64(ref), refSize)
+		}
+	}
+}
+
+// intSize returns the smallest number of bytes, 1, 2, 4 or 8, able to hold n.
+func intSize(n uint64) int {
+	switch {
+	case n <= math.MaxUint8:
+		return 1
+	case n <= math.MaxUint16:
+		return 2
+	case n <= math.MaxUint32:
+		return 4
+	}
+
+	return 8
+}
+
+// writeUint writes n as a big-endian unsigned integer of the given size.
+func writeUint(buf *bytes.Buffer, n uint64, size int) {
+	data := make([]byte, 8)
+	binary.BigEndian.PutUint64(data, n)
+	buf.Write(data[8-size:])
+}
+
+// writeInt writes an integer object. Negative numbers always use 8 bytes.
+func writeInt(buf *bytes.Buffer, n int64) {
+	if n < 0 {
+		buf.WriteByte(0x13)
+		writeUint(buf, uint64(n), 8)
+		return
+	}
+
+	writeUnsigned(buf, uint64(n))
+}
+
+// writeUnsigned writes an integer object. Numbers greater than math.MaxInt64
+// use 16 bytes because 8-bytes integers are always signed.
+func writeUnsigned(buf *bytes.Buffer, n uint64) {
+	if n > math.MaxInt64 {
+		buf.WriteByte(0x14)
+		writeUint(buf, 0, 8)
+		writeUint(buf, n, 8)
+		return
+	}
+
+	size := intSize(n)
+
+	switch size {
+	case 1:
+		buf.WriteByte(0x10)
+	case 2:
+		buf.WriteByte(0x11)
+	case 4:
+		buf.WriteByte(0x12)
+	default:
+		buf.WriteByte(0x13)
+	}
+
+	writeUint(buf, n, size)
+}
+
+// writeCount writes the marker of a string, data, array or dictionary with the
+// number of elements. Counts greater than 14 are written as an integer object.
+func writeCount(buf *bytes.Buffer, marker byte, n int) {
+	if n < 0xf {
+		buf.WriteByte(marker | byte(n))
+		return
+	}
+
+	buf.WriteByte(marker | 0xf)
+	writeUnsigned(buf, uint64(n))
+}
+
+// writeString writes an ASCII string object, or a UTF-16 string object if the
+// text contains characters outside the ASCII range.
+func writeString(buf *bytes.Buffer, s string) {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			units := utf16.Encode([]rune(s))
+
+			writeCount(buf, 0x60, len(units))
+
+			for _, unit := range units {
+				writeUint(buf, uint64(unit), 2)
+			}
+
+			return
+		}
+	}
+
+	writeCount(buf, 0x50, len(s))
+	buf.WriteString(s)
+}
@@ -0,0 +1,15 @@
+package plist
+
+import (
+	"bytes"
+	"testing"
+)
+
+func FuzzDecode(f *testing.F) {
+	f.Add(_trailer)
+	f.Fuzz(func(t *testing.T, a []byte) {
+		out, err := NewDecoder(bytes.NewReader(a)).Decode()
+		t.Logf("out: %#v\n", out)
+		t.Logf("err: %s\n", err)
+	})
+}
@@ -0,0 +1,46 @@
+package plist
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// magic are the bytes representing the signature of a binary property list,
+// the only version supported is "00".
+var magic []byte = []byte("bplist00")
+
+// trailerSize is the number of bytes at the end of the property list with the
+// size of the integers in the offset table, the number of objects, the top
+// object and the location of the offset table.
+const trailerSize = 32
+
+// maxDepth is the maximum number of nested arrays and dictionaries encoded.
+const maxDepth = 512
+
+// timePadding is the Unix timestamp until Jan 2001 when Mac epoch starts.
+const timePadding = 978307200
+
+// UID represents a reference to another object in a keyed archive, usually
+// created by NSKeyedArchiver. The value is the index of the object in the
+// "$objects" array of the archive.
+type UID uint64
+
+// decodeDate converts Mac epoch time into a time.Time.
+func decodeDate(number float64) (interface{}, error) {
+	// NOTES(cixtor): dates beyond the range of int64 nanoseconds cannot be
+	// represented without wrapping around, so they are considered invalid.
+	if math.IsNaN(number) || math.IsInf(number, 0) || math.Abs(number) > math.MaxInt64/1e9-timePadding {
+		return nil, fmt.Errorf("bplist invalid date %v", number)
+	}
+
+	sec, frac := math.Modf(number)
+	nsec := math.Round(frac * 1e9)
+
+	return time.Unix(int64(sec)+timePadding, int64(nsec)), nil
+}
+
+// encodeDate converts a time.Time into Mac epoch time.
+func encodeDate(t time.Time) float64 {
+	return float64(t.Unix()-timePadding) + float64(t.Nanosecond())/1e9
+}
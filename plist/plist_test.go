@@ -0,0 +1,134 @@
+package plist
+
+import (
+	"bytes"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+var _trailer = []byte{
+	0x62, 0x70, 0x6c, 0x69, 0x73, 0x74, 0x30, 0x30, 0xd1, 0x01, 0x02, 0x5f, 0x10, 0x18, 0x4e, 0x53,
+	0x48, 0x54, 0x54, 0x50, 0x43, 0x6f, 0x6f, 0x6b, 0x69, 0x65, 0x41, 0x63, 0x63, 0x65, 0x70, 0x74,
+	0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x10, 0x02, 0x08, 0x0b, 0x26, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x01, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x03, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x28,
+}
+
+func TestDecode(t *testing.T) {
+	out, err := NewDecoder(bytes.NewReader(_trailer)).Decode()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]interface{}{"NSHTTPCookieAcceptPolicy": int64(2)}
+
+	if !reflect.DeepEqual(out, expected) {
+		t.Fatalf("incorrect property list\n- %#v\n+ %#v", expected, out)
+	}
+}
+
+func TestEncode(t *testing.T) {
+	var buf bytes.Buffer
+
+	if err := NewEncoder(&buf).Encode(map[string]interface{}{"NSHTTPCookieAcceptPolicy": 2}); err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(buf.Bytes(), _trailer) {
+		t.Fatalf("incorrect property list\n- %#v\n+ %#v", _trailer, buf.Bytes())
+	}
+}
+
+func TestEncodeDecode(t *testing.T) {
+	value := map[string]interface{}{
+		"null":                  nil,
+		"true":                  true,
+		"false":                 false,
+		"int8":                  int64(-8),
+		"int16":                 int64(300),
+		"int32":                 int64(70000),
+		"int64":                 int64(math.MaxInt64),
+		"uint64":                uint64(math.MaxUint64),
+		"real":                  3.25,
+		"date":                  time.Date(2021, time.March, 4, 5, 6, 7, 500000000, time.UTC),
+		"data":                  []byte{0x0, 0x1, 0x2},
+		"ascii":                 "Lorem ipsum dolor sit amet",
+		"utf16":                 "Grüße, 世界",
+		"uid":                   UID(1024),
+		"array":                 []interface{}{"a", int64(1), []interface{}{}},
+		"dict":                  map[string]interface{}{"nested": map[string]interface{}{}},
+		strings.Repeat("k", 20): strings.Repeat("v", 300),
+	}
+
+	var buf bytes.Buffer
+
+	if err := NewEncoder(&buf).Encode(value); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := NewDecoder(&buf).Decode()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dict := out.(map[string]interface{})
+
+	if date, ok := dict["date"].(time.Time); !ok || !date.Equal(value["date"].(time.Time)) {
+		t.Fatalf("incorrect date\n- %s\n+ %v", value["date"], dict["date"])
+	}
+
+	delete(dict, "date")
+	delete(value, "date")
+
+	if !reflect.DeepEqual(dict, value) {
+		t.Fatalf("incorrect property list\n- %#v\n+ %#v", value, dict)
+	}
+}
+
+func TestEncodeUnsupported(t *testing.T) {
+	tests := []interface{}{
+		struct{}{},
+		map[int]string{1: "one"},
+		[]interface{}{make(chan int)},
+	}
+
+	for _, value := range tests {
+		if err := NewEncoder(&bytes.Buffer{}).Encode(value); err == nil {
+			t.Fatalf("unsupported value %#v should return an error", value)
+		}
+	}
+}
+
+func TestDecodeCycle(t *testing.T) {
+	// NOTES(cixtor): an array whose only element is the array itself.
+	data := []byte{
+		0x62, 0x70, 0x6c, 0x69, 0x73, 0x74, 0x30, 0x30, 0xa1, 0x00, 0x08,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0x01, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x0a,
+	}
+
+	if _, err := NewDecoder(bytes.NewReader(data)).Decode(); err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Fatalf("property list with a cycle should return an error, got %v", err)
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	tests := map[string][]byte{
+		"empty":     {},
+		"truncated": _trailer[:len(_trailer)-1],
+		"signature": append([]byte("bplist01"), _trailer[8:]...),
+		"object":    append(append([]byte{}, _trailer[:8]...), append([]byte{0x7f}, _trailer[9:]...)...),
+	}
+
+	for name, data := range tests {
+		if _, err := NewDecoder(bytes.NewReader(data)).Decode(); err == nil {
+			t.Fatalf("invalid property list (%s) should return an error", name)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package binarycookies
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/cixtor/binarycookies/plist"
+)
+
+// AcceptPolicy represents the NSHTTPCookieAcceptPolicy setting stored in the
+// binary property list at the end of the file, it determines which cookies
+// are accepted by all tasks within sessions based on this configuration.
+//
+// Ref: https://developer.apple.com/documentation/foundation/nshttpcookieacceptpolicy
+type AcceptPolicy int64
+
+const (
+	// AcceptPolicyAlways accepts all cookies.
+	AcceptPolicyAlways AcceptPolicy = 0
+	// AcceptPolicyNever rejects all cookies.
+	AcceptPolicyNever AcceptPolicy = 1
+	// AcceptPolicyOnlyFromMainDocumentDomain accepts cookies only from the
+	// main document domain.
+	AcceptPolicyOnlyFromMainDocumentDomain AcceptPolicy = 2
+)
+
+// acceptPolicyKey is the dictionary key holding the cookie accept policy.
+const acceptPolicyKey = "NSHTTPCookieAcceptPolicy"
+
+func (p AcceptPolicy) String() string {
+	switch p {
+	case AcceptPolicyAlways:
+		return "Always"
+	case AcceptPolicyNever:
+		return "Never"
+	case AcceptPolicyOnlyFromMainDocumentDomain:
+		return "OnlyFromMainDocumentDomain"
+	}
+
+	return fmt.Sprintf("AcceptPolicy(%d)", int64(p))
+}
+
+// Policy represents the binary property list at the end of the file.
+type Policy struct {
+	// AcceptPolicy is the cookie accept policy, or AcceptPolicyAlways if the
+	// dictionary does not contain the NSHTTPCookieAcceptPolicy key.
+	AcceptPolicy AcceptPolicy
+	// Dict is the entire dictionary, including keys unknown to this package.
+	Dict map[string]interface{}
+}
+
+// Policy returns the decoded binary property list found after the footer, or
+// nil if the file does not have one.
+func (b *BinaryCookies) Policy() *Policy {
+	return b.policy
+}
+
+// readPolicy decodes the binary property list stored in the trailer. The list
+// is prefixed by a 4-bytes big-endian integer representing its size.
+func (b *BinaryCookies) readPolicy() error {
+	if len(b.trailer) == 0 {
+		return nil
+	}
+
+	offset := b.offset - int64(len(b.trailer))
+
+	if len(b.trailer) < 4 {
+		return b.errorAt("trailer", offset, fmt.Errorf("size %q; %w", b.trailer, ErrBadTrailer))
+	}
+
+	size := binary.BigEndian.Uint32(b.trailer)
+
+	if uint64(size) > uint64(len(b.trailer)-4) {
+		return b.errorAt("trailer", offset, fmt.Errorf("size %d > %d; %w", size, len(b.trailer)-4, ErrBadTrailer))
+	}
+
+	value, err := plist.NewDecoder(bytes.NewReader(b.trailer[4 : 4+size])).Decode()
+
+	if err != nil {
+		return b.errorAt("trailer", offset+4, fmt.Errorf("%s; %w", err, ErrBadTrailer))
+	}
+
+	dict, ok := value.(map[string]interface{})
+
+	if !ok {
+		return b.errorAt("trailer", offset+4, fmt.Errorf("expected dictionary, got %T; %w", value, ErrBadTrailer))
+	}
+
+	policy := &Policy{Dict: dict}
+
+	switch n := dict[acceptPolicyKey].(type) {
+	case int64:
+		policy.AcceptPolicy = AcceptPolicy(n)
+	case uint64:
+		policy.AcceptPolicy = AcceptPolicy(n)
+	}
+
+	b.policy = policy
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package binarycookies
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestDecodePolicy(t *testing.T) {
+	cook := New(bytes.NewReader(_test1))
+
+	if _, err := cook.Decode(); err != nil {
+		t.Fatal(err)
+	}
+
+	policy := cook.Policy()
+
+	if policy == nil {
+		t.Fatal("binary property list is missing")
+	}
+
+	if policy.AcceptPolicy != AcceptPolicyOnlyFromMainDocumentDomain {
+		t.Fatalf("incorrect accept policy\n- %s\n+ %s", AcceptPolicyOnlyFromMainDocumentDomain, policy.AcceptPolicy)
+	}
+
+	if n, ok := policy.Dict["NSHTTPCookieAcceptPolicy"].(int64); !ok || n != 2 {
+		t.Fatalf("incorrect dictionary\n- %#v\n+ %#v", map[string]interface{}{"NSHTTPCookieAcceptPolicy": int64(2)}, policy.Dict)
+	}
+}
+
+func TestDecodeWithoutPolicy(t *testing.T) {
+	cook := New(bytes.NewReader(_test2))
+
+	if _, err := cook.Decode(); err != nil {
+		t.Fatal(err)
+	}
+
+	if policy := cook.Policy(); policy != nil {
+		t.Fatalf("unexpected binary property list %#v", policy)
+	}
+}
+
+func TestDecodeInvalidPolicy(t *testing.T) {
+	tests := map[string][]byte{
+		"size":      _test1[:len(_test1)-76],
+		"truncated": _test1[:len(_test1)-1],
+		"signature": append(append([]byte{}, _test1[:len(_test1)-75]...), make([]byte, 75)...),
+	}
+
+	for name, data := range tests {
+		if _, err := New(bytes.NewReader(data)).Decode(); err == nil {
+			t.Fatalf("invalid binary property list (%s) should return an error", name)
+		}
+	}
+}
+
+func TestDecodePolicyOverflow(t *testing.T) {
+	// NOTES(cixtor): a dictionary with 2^63 entries, twice the number of
+	// entries overflows and used to crash the binary property list decoder.
+	list := []byte("bplist00")
+	list = append(list, 0xdf, 0x13, 0x80, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x08)
+	list = append(list, make([]byte, 32)...)
+	list[len(list)-26] = 1
+	list[len(list)-25] = 1
+	list[len(list)-17] = 1
+	list[len(list)-1] = 18
+
+	var buf bytes.Buffer
+
+	trailer := append([]byte{0x0, 0x0, 0x0, byte(len(list))}, list...)
+
+	enc := NewEncoder(&buf)
+	enc.SetTrailer(trailer)
+
+	if err := enc.Encode(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := DecodeBytes(buf.Bytes()); !errors.Is(err, ErrBadTrailer) {
+		t.Fatalf("invalid binary property list should return %s, got %v", ErrBadTrailer, err)
+	}
+
+	findings := Validate(buf.Bytes())
+
+	if len(findings) != 1 || !errors.Is(findings[0].Err, ErrBadTrailer) {
+		t.Fatalf("invalid binary property list should be reported as %s, got %v", ErrBadTrailer, findings)
+	}
+}
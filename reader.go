@@ -0,0 +1,232 @@
+package binarycookies
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// PageReader provides random access to the pages of a binary cookies archive.
+//
+// Unlike Decode, which reads the file from beginning to end, the PageReader
+// locates every page using the page size table in the header, and every
+// cookie using the offset table at the beginning of the page. This allows to
+// load pages on demand, and to decode files with padding between records or
+// with cookies stored in a different order than their offsets.
+type PageReader struct {
+	r      io.ReaderAt
+	sizes  []uint32
+	starts []int64
+}
+
+// NewPageReader returns a PageReader reading from r, which is assumed to have
+// the given size in bytes. The header is read and validated immediately.
+func NewPageReader(r io.ReaderAt, size int64) (*PageReader, error) {
+	dec := New(io.NewSectionReader(r, 0, size))
+
+	if err := dec.readSignature(); err != nil {
+		return nil, err
+	}
+
+	if err := dec.readPageSize(); err != nil {
+		return nil, err
+	}
+
+	if err := dec.readAllPages(); err != nil {
+		return nil, err
+	}
+
+	starts := make([]int64, len(dec.page))
+	offset := dec.offset
+
+	for i, n := range dec.page {
+		starts[i] = offset
+		offset += int64(n)
+
+		if offset > size {
+			err := fmt.Errorf("page ends at %d beyond file size %d; %w", offset, size, ErrTruncated)
+			return nil, &DecodeError{Field: "page size", Page: i, Cookie: -1, Offset: int64(8 + 4*i), Err: err}
+		}
+	}
+
+	return &PageReader{
+		r:      r,
+		sizes:  dec.page,
+		starts: starts,
+	}, nil
+}
+
+// NumPages returns the number of pages in the file.
+func (p *PageReader) NumPages() int {
+	return len(p.sizes)
+}
+
+// Page reads and returns the page at the given index.
+func (p *PageReader) Page(index int) (Page, error) {
+	if index < 0 || index >= len(p.sizes) {
+		return Page{}, fmt.Errorf("Page index %d out of range [0, %d)", index, len(p.sizes))
+	}
+
+	return readPageAt(p.r, p.starts[index], int64(p.sizes[index]), p.starts[index], index, DecoderOptions{}, nil)
+}
+
+// Pages reads and returns all the pages in the file.
+func (p *PageReader) Pages() ([]Page, error) {
+	pages := make([]Page, len(p.sizes))
+
+	for i := range pages {
+		page, err := p.Page(i)
+
+		if err != nil {
+			return nil, err
+		}
+
+		pages[i] = page
+	}
+
+	return pages, nil
+}
+
+// readPageAt reads the page stored in r at the given offset and size, which
+// corresponds to the given position in the file, using the given options. If
+// fail is not nil, cookies that cannot be decoded are reported and skipped.
+func readPageAt(r io.ReaderAt, start int64, size int64, pos int64, index int, opts DecoderOptions, fail func(error)) (Page, error) {
+	page, err := newDecoderAt(r, start, size, pos, index, -1, opts).readPageHeader()
+
+	if err != nil {
+		return Page{}, err
+	}
+
+	page.Start = pos
+	page.Size = uint32(size)
+	page.End = pos + size
+
+	cookies := make([]Cookie, 0, len(page.Offsets))
+	indexes := make([]int, 0, len(page.Offsets))
+
+	for i, n := range page.Offsets {
+		offset := int64(n)
+
+		var cookie Cookie
+
+		if offset >= size {
+			err = fmt.Errorf("%d out of page size %d; %w", offset, size, ErrBadOffset)
+			err = &DecodeError{Field: "cookie offset", Page: index, Cookie: i, Offset: pos + int64(8+4*i), Err: err}
+		} else {
+			cookie, err = newDecoderAt(r, start+offset, size-offset, pos+offset, index, i, opts).readPageCookie()
+		}
+
+		if err != nil {
+			if fail == nil {
+				return Page{}, err
+			}
+
+			fail(err)
+			continue
+		}
+
+		cookies = append(cookies, cookie)
+		indexes = append(indexes, i)
+	}
+
+	// NOTES(cixtor): the cookies are not necessarily stored in the same order
+	// as their offsets, so the bytes between them are computed in the order
+	// they appear in the page. end is the position right after the page header
+	// or the last cookie visited so far, and a cookie starting before it would
+	// share its bytes with another record.
+	order := make([]int, len(cookies))
+
+	for i := range order {
+		order[i] = i
+	}
+
+	sort.SliceStable(order, func(i, j int) bool {
+		return cookies[order[i]].Start < cookies[order[j]].Start
+	})
+
+	end := pos + int64(4+4+4*len(page.Offsets)+4)
+	keep := make([]bool, len(cookies))
+
+	for _, i := range order {
+		cookie := &cookies[i]
+
+		if cookie.Start < end {
+			err = fmt.Errorf("cookie at %d overlaps the record ending at %d; %w", cookie.Start, end, ErrBadOffset)
+			err = &DecodeError{Field: "cookie offset", Page: index, Cookie: indexes[i], Offset: pos + int64(8+4*indexes[i]), Err: err}
+
+			if fail == nil {
+				return Page{}, err
+			}
+
+			fail(err)
+			continue
+		}
+
+		if cookie.Start > end {
+			if cookie.slack, err = readAt(r, start+end-pos, cookie.Start-end); err != nil {
+				return Page{}, err
+			}
+		}
+
+		end = cookie.End
+		keep[i] = true
+	}
+
+	page.Cookies = make([]Cookie, 0, len(cookies))
+
+	for i, cookie := range cookies {
+		if keep[i] {
+			page.Cookies = append(page.Cookies, cookie)
+		}
+	}
+
+	if page.End > end {
+		if page.slack, err = readAt(r, start+end-pos, page.End-end); err != nil {
+			return Page{}, err
+		}
+	}
+
+	if opts.RecoverSlack {
+		page.carveOrphans(opts)
+	}
+
+	return page, nil
+}
+
+// newDecoderAt returns a decoder for the given section of r. The section is at
+// the given position in the file, and belongs to the given page and cookie.
+func newDecoderAt(r io.ReaderAt, start int64, size int64, pos int64, page int, cookie int, opts DecoderOptions) *BinaryCookies {
+	dec := New(io.NewSectionReader(r, start, size))
+	dec.opts = opts
+
+	if data, ok := r.(byteSection); ok {
+		dec.input = data[start : start+size]
+		dec.zeroCopy = true
+	}
+
+	dec.offset = pos
+	dec.pageIndex = page
+	dec.cookieIndex = cookie
+
+	return dec
+}
+
+// byteSection is a page already read into memory. The decoders returned by
+// newDecoderAt slice the page instead of copying the cookie fields.
+type byteSection []byte
+
+func (s byteSection) ReadAt(p []byte, off int64) (int, error) {
+	return bytes.NewReader(s).ReadAt(p, off)
+}
+
+// readAt reads n bytes starting at the given offset.
+func readAt(r io.ReaderAt, offset int64, n int64) ([]byte, error) {
+	data := make([]byte, n)
+
+	if _, err := r.ReadAt(data, offset); err != nil {
+		return nil, fmt.Errorf("readAt %d bytes at %d; %w", n, offset, err)
+	}
+
+	return data, nil
+}
@@ -0,0 +1,154 @@
+package binarycookies
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestPageReader(t *testing.T) {
+	for _, data := range [][]byte{_test1, _test2} {
+		expected, err := New(bytes.NewReader(data)).Decode()
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		r, err := NewPageReader(bytes.NewReader(data), int64(len(data)))
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if r.NumPages() != len(expected) {
+			t.Fatalf("incorrect number of pages\n- %d\n+ %d", len(expected), r.NumPages())
+		}
+
+		pages, err := r.Pages()
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(pages, expected) {
+			t.Fatalf("incorrect pages\n- %#v\n+ %#v", expected, pages)
+		}
+	}
+}
+
+// reorderedFile returns a binary cookies file with two pages, where the cookie
+// offsets of the first page are swapped, so the second offset points to the
+// first cookie in the page and vice versa.
+func reorderedFile(tb testing.TB) []byte {
+	var buf bytes.Buffer
+
+	pages := []Page{
+		{
+			Cookies: []Cookie{
+				{Domain: []byte(`.example.com`), Name: []byte(`a`), Path: []byte(`/`), Value: []byte(`1`)},
+				{Domain: []byte(`.example.com`), Name: []byte(`b`), Path: []byte(`/`), Value: []byte(`2`)},
+			},
+		},
+		{
+			Cookies: []Cookie{
+				{Domain: []byte(`.example.org`), Name: []byte(`c`), Path: []byte(`/`), Value: []byte(`3`)},
+			},
+		},
+	}
+
+	if err := NewEncoder(&buf).Encode(pages); err != nil {
+		tb.Fatal(err)
+	}
+
+	// NOTES(cixtor): swap the offsets of the cookies in the first page, which
+	// starts right after the signature, the page count and two page sizes.
+	data := buf.Bytes()
+	first := data[16+8 : 16+12]
+	second := data[16+12 : 16+16]
+	a := binary.LittleEndian.Uint32(first)
+	binary.LittleEndian.PutUint32(first, binary.LittleEndian.Uint32(second))
+	binary.LittleEndian.PutUint32(second, a)
+
+	return data
+}
+
+func TestPageReaderReordered(t *testing.T) {
+	data := reorderedFile(t)
+
+	r, err := NewPageReader(bytes.NewReader(data), int64(len(data)))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	page, err := r.Page(0)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(page.Cookies[0].Name) != "b" || string(page.Cookies[1].Name) != "a" {
+		t.Fatalf("incorrect cookie order\n- %q %q\n+ %q %q", "b", "a", page.Cookies[0].Name, page.Cookies[1].Name)
+	}
+
+	page, err = r.Page(1)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(page.Cookies[0].Name) != "c" {
+		t.Fatalf("incorrect cookie name\n- %q\n+ %q", "c", page.Cookies[0].Name)
+	}
+
+	if _, err := r.Page(2); err == nil {
+		t.Fatal("page index out of range should return an error")
+	}
+
+	pages, err := r.Pages()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// NOTES(cixtor): the cookies are written in the order they were found,
+	// and the unused bytes must not contain them.
+	var buf bytes.Buffer
+
+	if err := NewEncoder(&buf).Encode(pages); err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(buf.Bytes(), data) {
+		t.Fatalf("incorrect encoding\n- %#v\n+ %#v", data, buf.Bytes())
+	}
+}
+
+func TestPageReaderOverlapping(t *testing.T) {
+	data := reorderedFile(t)
+
+	// NOTES(cixtor): both offsets in the first page point to the same cookie.
+	copy(data[16+12:16+16], data[16+8:16+12])
+
+	r, err := NewPageReader(bytes.NewReader(data), int64(len(data)))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var e *DecodeError
+
+	if _, err := r.Page(0); !errors.As(err, &e) || !errors.Is(err, ErrBadOffset) || e.Cookie != 1 {
+		t.Fatalf("overlapping cookies should return %s for cookie #1, got %v", ErrBadOffset, err)
+	}
+}
+
+func TestPageReaderTruncated(t *testing.T) {
+	data := _test2[:len(_test2)-400]
+
+	if _, err := NewPageReader(bytes.NewReader(data), int64(len(data))); err == nil {
+		t.Fatal("pages beyond the end of the file should return an error")
+	}
+}
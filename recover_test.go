@@ -0,0 +1,84 @@
+package binarycookies
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestRecoverCorrupted(t *testing.T) {
+	data := append([]byte{}, _test1...)
+
+	// NOTES(cixtor): page #1 starts at offset 64 and its second cookie starts
+	// 121 bytes later. Corrupt the end header of that cookie, and the page tag
+	// of page #2, which starts at offset 466.
+	data[185+36] = 0xff
+	data[466] = 0xff
+
+	if _, err := New(bytes.NewReader(data)).Decode(); err == nil {
+		t.Fatal("corrupted file should return an error")
+	}
+
+	cook := New(bytes.NewReader(data))
+	cook.RecoverCorrupted()
+
+	pages, err := cook.Decode()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(pages) != 11 {
+		t.Fatalf("incorrect number of pages\n- %d\n+ %d", 11, len(pages))
+	}
+
+	names := []string{}
+
+	for _, cookie := range pages[1].Cookies {
+		names = append(names, string(cookie.Name))
+	}
+
+	expected := []string{"httpOnly", "normal", "secure"}
+
+	if len(names) != len(expected) || names[0] != expected[0] || names[1] != expected[1] || names[2] != expected[2] {
+		t.Fatalf("incorrect recovered cookies\n- %q\n+ %q", expected, names)
+	}
+
+	errs := cook.Errors()
+
+	if len(errs) != 2 {
+		t.Fatalf("incorrect number of errors\n- %d\n+ %d: %v", 2, len(errs), errs)
+	}
+
+	if errs[0].Page != 1 || errs[0].Cookie != 1 || errs[0].Offset != 185+36 {
+		t.Fatalf("incorrect cookie error\n- page #1 cookie #1 at offset 221\n+ %s", errs[0])
+	}
+
+	if errs[1].Page != 2 || errs[1].Cookie != -1 || errs[1].Offset != 466 {
+		t.Fatalf("incorrect page error\n- page #2 at offset 466\n+ %s", errs[1])
+	}
+
+	if cook.Policy() == nil {
+		t.Fatal("binary property list is missing")
+	}
+}
+
+func TestRecoverTruncated(t *testing.T) {
+	data := _test2[:len(_test2)-400]
+
+	cook := New(bytes.NewReader(data))
+	cook.RecoverCorrupted()
+
+	pages, err := cook.Decode()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(pages[0].Cookies) == 0 {
+		t.Fatal("cookies before the truncation point should be recovered")
+	}
+
+	if len(cook.Errors()) == 0 {
+		t.Fatal("truncated file should report errors")
+	}
+}
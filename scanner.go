@@ -0,0 +1,302 @@
+package binarycookies
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"sort"
+)
+
+// scanner holds the state of the cookie-by-cookie decoder used by Next.
+type scanner struct {
+	started  bool
+	done     bool
+	buffered bool
+	err      error
+	page     Page
+	count    int
+	next     int
+	cookie   Cookie
+	orphans  []Cookie
+}
+
+// Next decodes the next cookie in the file, which is then available through
+// Cookie, and reports whether there was one. It returns false at the end of
+// the file or when an error occurs, in which case Err returns the error.
+//
+// Unlike Decode, which returns every page and cookie at once, Next keeps only
+// the header of the current page in memory, so it can be used to filter large
+// cookie stores as they are read. The checksum, the footer and the trailer
+// are read after the last cookie. Next and Decode must not be mixed.
+//
+// If the cookies of a page are not stored in the same order as their offsets,
+// Next reads the rest of the page into memory and decodes it the same way as
+// Decode, so both accept the same files.
+//
+// Example:
+//
+//	cook := binarycookies.New(file)
+//
+//	for cook.Next() {
+//		fmt.Println(cook.PageIndex(), cook.Cookie())
+//	}
+//
+//	if err := cook.Err(); err != nil {
+//		// handle error
+//	}
+func (b *BinaryCookies) Next() bool {
+	if b.scan.done {
+		return false
+	}
+
+	if err := b.scanNext(); err != nil {
+		b.scan.err = err
+		b.scan.done = true
+		b.endPage()
+		return false
+	}
+
+	return !b.scan.done
+}
+
+// Cookie returns the most recent cookie decoded by Next.
+func (b *BinaryCookies) Cookie() Cookie {
+	return b.scan.cookie
+}
+
+// PageIndex returns the index of the page containing the most recent cookie
+// decoded by Next, or -1 if there is none.
+func (b *BinaryCookies) PageIndex() int {
+	return b.pageIndex
+}
+
+// CookieIndex returns the index, within its page, of the most recent cookie
+// decoded by Next, or -1 if there is none.
+func (b *BinaryCookies) CookieIndex() int {
+	return b.cookieIndex
+}
+
+// Err returns the first error found by Next, if any.
+func (b *BinaryCookies) Err() error {
+	return b.scan.err
+}
+
+// scanNext moves the scanner forward until the next cookie is decoded or the
+// end of the file is reached.
+func (b *BinaryCookies) scanNext() error {
+	if !b.scan.started {
+		b.scan.started = true
+
+		if err := b.readSignature(); err != nil {
+			return err
+		}
+
+		if err := b.readPageSize(); err != nil {
+			return err
+		}
+
+		if err := b.readAllPages(); err != nil {
+			return err
+		}
+	}
+
+	for {
+		if len(b.scan.orphans) > 0 {
+			b.scan.cookie = b.scan.orphans[0]
+			b.scan.orphans = b.scan.orphans[1:]
+			return nil
+		}
+
+		if b.paging && b.scan.next < b.scan.count {
+			return b.scanCookie()
+		}
+
+		if b.paging {
+			if err := b.endPage(); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if next := b.pageIndex + 1; next < int(b.size) {
+			if err := b.beginPage(next); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		b.scan.done = true
+		b.pageIndex = -1
+
+		return b.readFooterAndTrailer()
+	}
+}
+
+// scanCookie decodes the next cookie in the current page. In recovery mode the
+// cookie was already decoded by beginPage, as well as when the page is read
+// into memory because its cookies are out of order.
+func (b *BinaryCookies) scanCookie() error {
+	var err error
+	var slack []byte
+
+	index := b.scan.next
+	b.scan.next++
+
+	b.cookieIndex = index
+
+	if b.scan.buffered {
+		b.scan.cookie = b.scan.page.Cookies[index]
+		return nil
+	}
+
+	// NOTES(cixtor): the page is read from beginning to end, a cookie stored
+	// before the previous one, or overlapping it, cannot be read again.
+	pos := b.pageStart + int64(b.scan.page.Offsets[index])
+
+	if pos < b.offset {
+		err = fmt.Errorf("%d before the previous record ending at %d; %w", pos, b.offset, ErrBadOffset)
+		return b.errorAt("cookie offset", b.pageStart+int64(8+4*index), err)
+	}
+
+	if pos > b.offset {
+		if slack, err = b.readSlack("cookie slack", pos-b.offset); err != nil {
+			return err
+		}
+
+		if b.opts.RecoverSlack {
+			orphans := carveSlack(slack, pos-int64(len(slack)), b.opts)
+			b.scan.page.Orphans = append(b.scan.page.Orphans, orphans...)
+		}
+	}
+
+	if b.scan.cookie, err = b.readPageCookie(); err != nil {
+		return err
+	}
+
+	if end := b.pageStart + int64(b.page[b.pageIndex]); b.scan.cookie.End > end {
+		err = fmt.Errorf("cookie ends at %d beyond page end %d; %w", b.scan.cookie.End, end, ErrBadOffset)
+		return b.errorAt("cookie", pos, err)
+	}
+
+	b.scan.cookie.slack = slack
+
+	return nil
+}
+
+// beginPage reads the header of the page at the given index. In recovery mode
+// the entire page is read and only the cookies that could be decoded remain.
+func (b *BinaryCookies) beginPage(index int) error {
+	var err error
+
+	b.paging = true
+	b.pageStart = b.offset
+	b.pageIndex = index
+	b.cookieIndex = -1
+	b.scan.next = 0
+	b.scan.buffered = b.opts.RecoverCorrupted
+
+	if b.opts.RecoverCorrupted {
+		// NOTES(cixtor): errors are recorded by readPage in recovery mode.
+		b.scan.page, _ = b.readPage(index)
+		b.scan.count = len(b.scan.page.Cookies)
+		return nil
+	}
+
+	if b.scan.page, err = b.readPageHeader(); err != nil {
+		return err
+	}
+
+	b.scan.count = len(b.scan.page.Offsets)
+
+	offsets := b.scan.page.Offsets
+
+	if !sort.SliceIsSorted(offsets, func(i, j int) bool { return offsets[i] < offsets[j] }) {
+		return b.bufferPage(index)
+	}
+
+	return nil
+}
+
+// bufferPage reads the rest of the current page, after the header, and decodes
+// the cookies using their offsets, the same way as readPage.
+func (b *BinaryCookies) bufferPage(index int) error {
+	var buf bytes.Buffer
+
+	size := int64(b.page[index])
+	header := b.offset - b.pageStart
+
+	if header > size {
+		err := fmt.Errorf("header of %d bytes exceeds page size %d; %w", header, size, ErrBadOffset)
+		return b.errorAt("page size", b.pageStart, err)
+	}
+
+	data, err := b.readSlack("page", size-header)
+
+	if err != nil {
+		return err
+	}
+
+	// NOTES(cixtor): the header was already validated, it is written again
+	// to locate the cookies with the same offsets used in the file.
+	buf.Write(pageTag)
+	writeUint32(&buf, binary.LittleEndian, b.scan.page.Length)
+
+	for _, n := range b.scan.page.Offsets {
+		writeUint32(&buf, binary.LittleEndian, n)
+	}
+
+	buf.Write([]byte{0x0, 0x0, 0x0, 0x0})
+	buf.Write(data)
+
+	page, err := readPageAt(byteSection(buf.Bytes()), 0, int64(buf.Len()), b.pageStart, index, b.opts, nil)
+
+	if err != nil {
+		return err
+	}
+
+	b.scan.page = page
+	b.scan.count = len(page.Cookies)
+	b.scan.buffered = true
+
+	return nil
+}
+
+// endPage reads the bytes between the last cookie and the end of the current
+// page, if any, and leaves the page. The orphaned cookies found in the page
+// are returned by Next before moving to the next page.
+func (b *BinaryCookies) endPage() error {
+	if !b.paging {
+		return nil
+	}
+
+	b.cookieIndex = -1
+
+	defer func() { b.paging = false }()
+
+	if b.scan.err != nil {
+		return nil
+	}
+
+	if end := b.pageStart + int64(b.page[b.pageIndex]); end > b.offset {
+		pos := b.offset
+		slack, err := b.readSlack("page slack", end-b.offset)
+
+		if err != nil {
+			return err
+		}
+
+		// NOTES(cixtor): in recovery mode, or if the cookies are out of order,
+		// the page was decoded by beginPage, including the orphans, and the
+		// remaining bytes are always empty.
+		if b.opts.RecoverSlack {
+			orphans := carveSlack(slack, pos, b.opts)
+			b.scan.page.Orphans = append(b.scan.page.Orphans, orphans...)
+		}
+	}
+
+	b.scan.orphans = b.scan.page.Orphans
+
+	return nil
+}
@@ -0,0 +1,181 @@
+package binarycookies
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestNext(t *testing.T) {
+	for _, data := range [][]byte{_test1, _test2} {
+		pages, err := New(bytes.NewReader(data)).Decode()
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var expected []Cookie
+
+		for _, page := range pages {
+			expected = append(expected, page.Cookies...)
+		}
+
+		var cookies []Cookie
+
+		cook := New(bytes.NewReader(data))
+
+		for cook.Next() {
+			cookie := cook.Cookie()
+			index := cook.CookieIndex()
+
+			if !reflect.DeepEqual(cookie, pages[cook.PageIndex()].Cookies[index]) {
+				t.Fatalf("incorrect cookie context page #%d cookie #%d\n+ %s", cook.PageIndex(), index, cookie)
+			}
+
+			cookies = append(cookies, cookie)
+		}
+
+		if err := cook.Err(); err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(cookies, expected) {
+			t.Fatalf("incorrect cookies\n- %v\n+ %v", expected, cookies)
+		}
+
+		if !cook.ChecksumValid() {
+			t.Fatalf("incorrect checksum %#08x", cook.Checksum())
+		}
+
+		if cook.Next() {
+			t.Fatal("Next should return false after the end of the file")
+		}
+	}
+}
+
+func TestNextPolicy(t *testing.T) {
+	cook := New(bytes.NewReader(_test1))
+
+	for cook.Next() {
+	}
+
+	if err := cook.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	if policy := cook.Policy(); policy == nil || policy.AcceptPolicy != AcceptPolicyOnlyFromMainDocumentDomain {
+		t.Fatalf("incorrect policy\n- %s\n+ %v", AcceptPolicyOnlyFromMainDocumentDomain, policy)
+	}
+}
+
+func TestNextTruncated(t *testing.T) {
+	var names []string
+
+	cook := New(bytes.NewReader(_test2[:301]))
+
+	for cook.Next() {
+		names = append(names, string(cook.Cookie().Name))
+	}
+
+	if len(names) != 2 {
+		t.Fatalf("incorrect number of cookies before the error\n- %d\n+ %d", 2, len(names))
+	}
+
+	var e *DecodeError
+
+	if err := cook.Err(); !errors.As(err, &e) || !errors.Is(err, ErrTruncated) {
+		t.Fatalf("truncated file should return %s, got %v", ErrTruncated, err)
+	}
+
+	if e.Page != 0 || e.Cookie != 2 || e.Offset != 301 {
+		t.Fatalf("incorrect error position\n- page #0 cookie #2 at offset 301\n+ %s", e)
+	}
+}
+
+func TestNextRecoverCorrupted(t *testing.T) {
+	data := append([]byte{}, _test1...)
+	data[185+36] = 0xff
+	data[466] = 0xff
+
+	cook := New(bytes.NewReader(data))
+	cook.RecoverCorrupted()
+
+	pages, err := cook.Decode()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var expected []Cookie
+
+	for _, page := range pages {
+		expected = append(expected, page.Cookies...)
+	}
+
+	var cookies []Cookie
+
+	cook = New(bytes.NewReader(data))
+	cook.RecoverCorrupted()
+
+	for cook.Next() {
+		cookies = append(cookies, cook.Cookie())
+	}
+
+	if err := cook.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(cookies, expected) {
+		t.Fatalf("incorrect cookies\n- %v\n+ %v", expected, cookies)
+	}
+
+	if len(cook.Errors()) != 2 {
+		t.Fatalf("incorrect number of errors\n- %d\n+ %d: %v", 2, len(cook.Errors()), cook.Errors())
+	}
+}
+
+func TestNextInvalidFile(t *testing.T) {
+	cook := New(bytes.NewReader(_test3))
+
+	if cook.Next() {
+		t.Fatal("invalid binary cookies file should not return cookies")
+	}
+
+	if err := cook.Err(); !errors.Is(err, ErrBadSignature) {
+		t.Fatalf("invalid binary cookies file should return %s, got %v", ErrBadSignature, err)
+	}
+}
+
+func TestNextReordered(t *testing.T) {
+	data := reorderedFile(t)
+	cook := New(bytes.NewReader(data))
+
+	var names []string
+
+	for cook.Next() {
+		names = append(names, string(cook.Cookie().Name))
+	}
+
+	if err := cook.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	pages, err := New(bytes.NewReader(data)).Decode()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var expected []string
+
+	for _, page := range pages {
+		for _, cookie := range page.Cookies {
+			expected = append(expected, string(cookie.Name))
+		}
+	}
+
+	if !reflect.DeepEqual(names, expected) {
+		t.Fatalf("cookies out of order should be decoded the same as Decode\n- %v\n+ %v", expected, names)
+	}
+}
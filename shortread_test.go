@@ -0,0 +1,59 @@
+package binarycookies
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+	"testing/iotest"
+)
+
+func TestDecodeShortReads(t *testing.T) {
+	readers := map[string]func(io.Reader) io.Reader{
+		"HalfReader":    iotest.HalfReader,
+		"OneByteReader": iotest.OneByteReader,
+		"DataErrReader": iotest.DataErrReader,
+	}
+
+	for _, data := range [][]byte{_test1, _test2} {
+		expected, err := New(bytes.NewReader(data)).Decode()
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for name, reader := range readers {
+			cook := New(reader(bytes.NewReader(data)))
+			cook.DisallowInvalidChecksum()
+
+			pages, err := cook.Decode()
+
+			if err != nil {
+				t.Fatalf("%s: %s", name, err)
+			}
+
+			if !reflect.DeepEqual(pages, expected) {
+				t.Fatalf("%s: incorrect pages\n- %#v\n+ %#v", name, expected, pages)
+			}
+		}
+	}
+}
+
+func TestDecodeShortReadsTruncated(t *testing.T) {
+	for _, n := range []int{2, 10, 100, 301, 600, len(_test2) - 2} {
+		r := iotest.OneByteReader(bytes.NewReader(_test2[:n]))
+
+		if _, err := New(r).Decode(); !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Fatalf("file truncated at %d bytes should return %s, got %v", n, io.ErrUnexpectedEOF, err)
+		}
+	}
+}
+
+func TestDecodeReadError(t *testing.T) {
+	r := io.MultiReader(bytes.NewReader(_test2[:100]), iotest.ErrReader(iotest.ErrTimeout))
+
+	if _, err := New(r).Decode(); !errors.Is(err, iotest.ErrTimeout) {
+		t.Fatalf("read errors should be returned\n- %s\n+ %v", iotest.ErrTimeout, err)
+	}
+}
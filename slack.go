@@ -0,0 +1,57 @@
+package binarycookies
+
+// carveSlack returns the cookies found in the unused bytes of a page, which
+// start at the given position in the file. Every position is checked with the
+// same rules used by the Carver, and the cookies are marked as orphaned.
+func carveSlack(slack []byte, pos int64, opts DecoderOptions) []Cookie {
+	var orphans []Cookie
+
+	for p := 0; p+cookieHeaderSize <= len(slack); p++ {
+		cookie, ok := carveCookieAt(slack[p:], pos+int64(p), opts)
+
+		if !ok {
+			continue
+		}
+
+		cookie.Orphaned = true
+		orphans = append(orphans, cookie)
+
+		p += int(cookie.Size) - 1
+	}
+
+	return orphans
+}
+
+// carveOrphans looks for old cookies in the bytes before every cookie and at
+// the end of the page, and stores them in page.Orphans in the order they
+// appear in the file. Cookies overlapping the bytes of a cookie referenced by
+// the page are not orphans and are skipped.
+func (page *Page) carveOrphans(opts DecoderOptions) {
+	var orphans []Cookie
+
+	page.Orphans = nil
+
+	for _, cookie := range page.Cookies {
+		orphans = append(orphans, carveSlack(cookie.slack, cookie.Start-int64(len(cookie.slack)), opts)...)
+	}
+
+	orphans = append(orphans, carveSlack(page.slack, page.End-int64(len(page.slack)), opts)...)
+
+	for _, orphan := range orphans {
+		if !page.referenced(orphan.Start, orphan.End) {
+			page.Orphans = append(page.Orphans, orphan)
+		}
+	}
+}
+
+// referenced reports whether the given range of bytes overlaps any of the
+// cookies referenced by the page.
+func (page *Page) referenced(start int64, end int64) bool {
+	for _, cookie := range page.Cookies {
+		if start < cookie.End && cookie.Start < end {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,177 @@
+package binarycookies
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+// slackFile returns _test1 with two old cookies in the unused bytes of the
+// second page, one before the second cookie and one at the end of the page.
+func slackFile(t *testing.T) []byte {
+	pages, err := New(bytes.NewReader(_test1)).Decode()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pages[1].Cookies[1].slack = append([]byte{0x0, 0x0, 0x0}, _test1[92:185]...)
+	pages[1].slack = append(append([]byte{}, _test1[185:284]...), 0x0, 0x0, 0x0, 0x0, 0x0)
+
+	var buf bytes.Buffer
+
+	enc := NewEncoder(&buf)
+	enc.SetTrailer(_test1[len(_test1)-79:])
+
+	if err := enc.Encode(pages); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestRecoverSlack(t *testing.T) {
+	data := slackFile(t)
+
+	pages, err := New(bytes.NewReader(data)).Decode()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, page := range pages {
+		if len(page.Orphans) != 0 {
+			t.Fatalf("orphans should only be recovered with RecoverSlack, got %v", page.Orphans)
+		}
+	}
+
+	original, err := New(bytes.NewReader(_test1)).Decode()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Cookie{original[1].Cookies[0], original[1].Cookies[1]}
+	expected[0].Start = pages[1].Cookies[1].Start - 93
+	expected[1].Start = pages[1].End - 5 - 99
+
+	for i := range expected {
+		expected[i].End = expected[i].Start + int64(expected[i].Size)
+		expected[i].Orphaned = true
+	}
+
+	tests := []struct {
+		name string
+		dec  *BinaryCookies
+	}{
+		{"Decode", NewDecoder(bytes.NewReader(data), &DecoderOptions{RecoverSlack: true})},
+		{"Workers", NewDecoder(bytes.NewReader(data), &DecoderOptions{RecoverSlack: true, Workers: 2})},
+		{"RecoverCorrupted", NewDecoder(bytes.NewReader(data), &DecoderOptions{RecoverSlack: true, RecoverCorrupted: true})},
+		{"DecodeBytes", NewDecoderBytes(data, &DecoderOptions{RecoverSlack: true})},
+	}
+
+	for _, test := range tests {
+		pages, err := test.dec.Decode()
+
+		if err != nil {
+			t.Fatalf("%s: %s", test.name, err)
+		}
+
+		if !reflect.DeepEqual(pages[1].Orphans, expected) {
+			t.Fatalf("%s: incorrect orphans\n- %v\n+ %v", test.name, expected, pages[1].Orphans)
+		}
+
+		for i, page := range pages {
+			if i != 1 && len(page.Orphans) != 0 {
+				t.Fatalf("%s: incorrect orphans in page at %d\n+ %v", test.name, page.Start, page.Orphans)
+			}
+		}
+
+		var buf bytes.Buffer
+
+		enc := NewEncoder(&buf)
+		enc.SetTrailer(test.dec.Trailer())
+
+		if err := enc.Encode(pages); err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(buf.Bytes(), data) {
+			t.Fatalf("%s: orphans should not change the encoding", test.name)
+		}
+	}
+}
+
+func TestRecoverSlackNext(t *testing.T) {
+	data := slackFile(t)
+
+	for _, corrupted := range []bool{false, true} {
+		pages, err := NewDecoder(bytes.NewReader(data), &DecoderOptions{RecoverSlack: true, RecoverCorrupted: corrupted}).Decode()
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var expected []Cookie
+
+		for _, page := range pages {
+			expected = append(expected, page.Cookies...)
+			expected = append(expected, page.Orphans...)
+		}
+
+		var cookies []Cookie
+
+		cook := NewDecoder(bytes.NewReader(data), &DecoderOptions{RecoverSlack: true, RecoverCorrupted: corrupted})
+
+		for cook.Next() {
+			cookie := cook.Cookie()
+
+			if cookie.Orphaned && cook.CookieIndex() != -1 {
+				t.Fatalf("orphaned cookie should have no index, got %d", cook.CookieIndex())
+			}
+
+			cookies = append(cookies, cookie)
+		}
+
+		if err := cook.Err(); err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(cookies, expected) {
+			t.Fatalf("incorrect cookies with recovery %t\n- %v\n+ %v", corrupted, expected, cookies)
+		}
+	}
+}
+
+func TestRecoverSlackReferenced(t *testing.T) {
+	pages, err := New(bytes.NewReader(_test1)).Decode()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// NOTES(cixtor): the unused bytes of the page include both cookies, which
+	// are referenced by the page and must not be reported as orphans.
+	page := pages[1]
+	page.slack = _test1[page.Cookies[0].Start:page.End]
+	page.carveOrphans(DecoderOptions{})
+
+	if len(page.Orphans) != 0 {
+		t.Fatalf("referenced cookies should not be orphans, got %v", page.Orphans)
+	}
+
+	for _, workers := range []int{1, 2} {
+		opts := DecoderOptions{Workers: workers, RecoverSlack: true}
+		pages, err := NewDecoder(bytes.NewReader(reorderedFile(t)), &opts).Decode()
+
+		if err != nil {
+			t.Fatalf("%d workers: %s", workers, err)
+		}
+
+		for _, page := range pages {
+			if len(page.Orphans) != 0 {
+				t.Fatalf("%d workers: referenced cookies should not be orphans, got %v", workers, page.Orphans)
+			}
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package binarycookies
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// Stats is a summary of a binary cookies file returned by Stat.
+type Stats struct {
+	// Pages is the number of pages in the file.
+	Pages int
+
+	// Cookies is the number of cookies in the file.
+	Cookies int
+
+	// CookiesPerPage is the number of cookies in every page, in the same
+	// order as the pages in the file.
+	CookiesPerPage []int
+
+	// Size is the number of bytes in the file, including the trailer.
+	Size int64
+
+	// Trailer reports whether there are bytes after the footer, usually a
+	// binary property list with the cookie accept policy.
+	Trailer bool
+}
+
+// Stat returns the number of pages and cookies in the file, its size and
+// whether it has a trailer. See BinaryCookies.Stat.
+func Stat(r io.Reader) (Stats, error) {
+	return New(r).Stat()
+}
+
+// Stat reads the header of the file and of every page, and returns a summary
+// of its content without decoding any cookie. The bytes of every page after
+// the number of cookies are skipped, using Seek if the reader implements
+// io.Seeker, which allows to index thousands of files with little memory and
+// I/O. The page tags and the footer are validated, but the cookie offsets and
+// the checksum are not. Stat and Decode must not be mixed.
+func (b *BinaryCookies) Stat() (Stats, error) {
+	var stats Stats
+
+	if err := b.readSignature(); err != nil {
+		return stats, err
+	}
+
+	if err := b.readPageSize(); err != nil {
+		return stats, err
+	}
+
+	if err := b.readAllPages(); err != nil {
+		return stats, err
+	}
+
+	stats.Pages = len(b.page)
+	stats.CookiesPerPage = make([]int, len(b.page))
+
+	for i, size := range b.page {
+		count, err := b.statOnePage(i, size)
+
+		if err != nil {
+			return stats, err
+		}
+
+		stats.Cookies += count
+		stats.CookiesPerPage[i] = count
+	}
+
+	// NOTES(cixtor): the checksum cannot be verified without reading every
+	// page, so it is read as a regular field instead of using readChecksum.
+	data, err := b.readField("checksum", 4)
+
+	if err != nil {
+		return stats, err
+	}
+
+	b.checksum = binary.BigEndian.Uint32(data)
+
+	if err := b.readFooter(); err != nil {
+		return stats, err
+	}
+
+	offset := b.offset
+
+	if err := b.skip("trailer", -1); err != nil {
+		return stats, err
+	}
+
+	stats.Size = b.offset
+	stats.Trailer = b.offset > offset
+
+	return stats, nil
+}
+
+// statOnePage reads the page tag and the number of cookies of the page at the
+// given index, then skips the rest of the page.
+func (b *BinaryCookies) statOnePage(index int, size uint32) (int, error) {
+	start := b.offset
+
+	b.pageIndex = index
+
+	defer func() { b.pageIndex = -1 }()
+
+	if size < 8 {
+		return 0, b.errorAt("page size", int64(8+4*index), fmt.Errorf("%d bytes too small for a page header; %w", size, ErrTruncated))
+	}
+
+	data, err := b.readField("page tag", 4)
+
+	if err != nil {
+		return 0, err
+	}
+
+	if !bytes.Equal(data, pageTag) && !b.opts.Lenient {
+		return 0, b.errorAt("page tag", start, ErrBadPageTag)
+	}
+
+	if data, err = b.readField("cookie count", 4); err != nil {
+		return 0, err
+	}
+
+	count := binary.LittleEndian.Uint32(data)
+
+	if err := checkLimit(int64(count), int64(b.opts.MaxCookiesPerPage)); err != nil {
+		return 0, b.errorAt("cookie count", b.offset-4, err)
+	}
+
+	// NOTES(cixtor): every cookie needs at least one offset in the header,
+	// a larger number means the page is corrupted.
+	if header := 12 + 4*int64(count); header > int64(size) {
+		return 0, b.errorAt("cookie count", b.offset-4, fmt.Errorf("%d cookies in %d bytes; %w", count, size, ErrTruncated))
+	}
+
+	if err := b.skip("page", int64(size)-8); err != nil {
+		return 0, err
+	}
+
+	return int(count), nil
+}
+
+// skip moves n bytes forward in the file without reading them, or until the
+// end of the file if n is negative. If the file ends before, the error is the
+// same as the one returned by io.CopyN.
+func (b *BinaryCookies) skip(field string, n int64) error {
+	var m int64
+	var err error
+
+	offset := b.offset
+	seeker, ok := b.file.(io.Seeker)
+
+	switch {
+	case b.zeroCopy:
+		if n < 0 {
+			n = int64(len(b.input))
+		}
+
+		var data []byte
+
+		data, err = b.slice(n)
+		m = int64(len(data))
+	case ok:
+		// NOTES(cixtor): seeking beyond the end of a file is not an error, so
+		// the size of the file is checked first to report truncated pages.
+		var cur, end int64
+
+		if cur, err = seeker.Seek(0, io.SeekCurrent); err != nil {
+			break
+		}
+
+		if end, err = seeker.Seek(0, io.SeekEnd); err != nil {
+			break
+		}
+
+		if n >= 0 && cur+n < end {
+			end = cur + n
+		} else if n >= 0 && cur+n > end {
+			err = io.EOF
+		}
+
+		if _, serr := seeker.Seek(end, io.SeekStart); serr != nil {
+			err = serr
+			break
+		}
+
+		m = end - cur
+	case n < 0:
+		m, err = io.Copy(io.Discard, b.file)
+	default:
+		m, err = io.CopyN(io.Discard, b.file, n)
+	}
+
+	b.offset += m
+
+	if err != nil {
+		return b.errorAt(field, offset, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package binarycookies
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+	"testing/iotest"
+)
+
+func TestStat(t *testing.T) {
+	for _, data := range [][]byte{_test1, _test2} {
+		dec := New(bytes.NewReader(data))
+		pages, err := dec.Decode()
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := Stats{
+			Pages:          len(pages),
+			CookiesPerPage: make([]int, len(pages)),
+			Size:           int64(len(data)),
+			Trailer:        len(dec.Trailer()) > 0,
+		}
+
+		for i, page := range pages {
+			expected.Cookies += len(page.Cookies)
+			expected.CookiesPerPage[i] = len(page.Cookies)
+		}
+
+		tests := []struct {
+			name string
+			dec  *BinaryCookies
+		}{
+			{"Seeker", New(bytes.NewReader(data))},
+			{"Reader", New(iotest.OneByteReader(bytes.NewReader(data)))},
+			{"Bytes", NewDecoderBytes(data, nil)},
+		}
+
+		for _, test := range tests {
+			stats, err := test.dec.Stat()
+
+			if err != nil {
+				t.Fatalf("%s: %s", test.name, err)
+			}
+
+			if !reflect.DeepEqual(stats, expected) {
+				t.Fatalf("%s: incorrect stats\n- %#v\n+ %#v", test.name, expected, stats)
+			}
+		}
+	}
+}
+
+func TestStatErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []byte
+		target   error
+		expected DecodeError
+	}{
+		{"Signature", _test3, ErrBadSignature, DecodeError{Field: "signature", Page: -1, Cookie: -1, Offset: 0}},
+		{"PageTag", corrupt(_test1, 466, 0xff), ErrBadPageTag, DecodeError{Field: "page tag", Page: 2, Cookie: -1, Offset: 466}},
+		{"CookieCount", corrupt(_test1, 68, 0xff), ErrTruncated, DecodeError{Field: "cookie count", Page: 1, Cookie: -1, Offset: 68}},
+		{"Footer", corrupt(_test1, 578, 0xff), ErrBadFooter, DecodeError{Field: "footer", Page: -1, Cookie: -1, Offset: 578}},
+		{"Truncated", _test1[:300], ErrTruncated, DecodeError{Field: "page", Page: 1, Cookie: -1, Offset: 72}},
+	}
+
+	for _, test := range tests {
+		decoders := []*BinaryCookies{
+			New(bytes.NewReader(test.data)),
+			New(iotest.OneByteReader(bytes.NewReader(test.data))),
+			NewDecoderBytes(test.data, nil),
+		}
+
+		for _, dec := range decoders {
+			_, err := dec.Stat()
+
+			if !errors.Is(err, test.target) {
+				t.Fatalf("%s: incorrect error\n- %s\n+ %v", test.name, test.target, err)
+			}
+
+			var e *DecodeError
+
+			if !errors.As(err, &e) {
+				t.Fatalf("%s: error is not a DecodeError %#v", test.name, err)
+			}
+
+			e.Err = nil
+
+			if *e != test.expected {
+				t.Fatalf("%s: incorrect error context\n- %#v\n+ %#v", test.name, test.expected, *e)
+			}
+		}
+	}
+}
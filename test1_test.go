@@ -91,10 +91,14 @@ func Test1DecodeChecksum(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	expected := []byte{0x0, 0x0, 0x16, 0x33, 0x7, 0x17, 0x20, 0x5}
+	expected := uint32(0x1633)
 
-	if !bytes.Equal(cook.checksum, expected) {
-		t.Fatalf("incorrect file checksum\n- %#v\n+ %#v", expected, cook.checksum)
+	if cook.Checksum() != expected {
+		t.Fatalf("incorrect file checksum\n- %#08x\n+ %#08x", expected, cook.Checksum())
+	}
+
+	if !cook.ChecksumValid() {
+		t.Fatalf("incorrect computed checksum\n- %#08x\n+ %#08x", expected, cook.computed)
 	}
 }
 
@@ -178,3 +182,37 @@ func Test1DecodePage9(t *testing.T) {
 func Test1DecodePage10(t *testing.T) {
 	checkCookiePage(t, _test1, 10, Page{Length: 0, Offsets: []uint32{}, Cookies: []Cookie{}})
 }
+
+func Test1DecodePositions(t *testing.T) {
+	pages, err := New(bytes.NewReader(_test1)).Decode()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if page := pages[1]; page.Start != 64 || page.Size != 402 || page.End != 466 {
+		t.Fatalf("incorrect page position\n- start 64 size 402 end 466\n+ start %d size %d end %d", page.Start, page.Size, page.End)
+	}
+
+	starts := []int64{92, 185, 284, 375}
+
+	for i, cookie := range pages[1].Cookies {
+		if cookie.Start != starts[i] || cookie.End != cookie.Start+int64(cookie.Size) {
+			t.Fatalf("incorrect cookie #%d position\n- start %d size %d\n+ start %d end %d", i, starts[i], cookie.Size, cookie.Start, cookie.End)
+		}
+
+		if cookie.CommentOffset != 0 || cookie.DomainOffset != 56 || cookie.NameOffset != 56+uint32(len(cookie.Domain))+1 {
+			t.Fatalf("incorrect cookie #%d string offsets %d %d %d", i, cookie.CommentOffset, cookie.DomainOffset, cookie.NameOffset)
+		}
+
+		if domain := _test1[cookie.Start+int64(cookie.DomainOffset) : cookie.Start+int64(cookie.NameOffset)-1]; !bytes.Equal(domain, cookie.Domain) {
+			t.Fatalf("incorrect cookie #%d domain position\n- %s\n+ %s", i, cookie.Domain, domain)
+		}
+	}
+
+	for i := 1; i < len(pages); i++ {
+		if pages[i].Start != pages[i-1].End {
+			t.Fatalf("page #%d should start at %d, got %d", i, pages[i-1].End, pages[i].Start)
+		}
+	}
+}
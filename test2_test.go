@@ -2,6 +2,7 @@ package binarycookies
 
 import (
 	"bytes"
+	"errors"
 	"testing"
 )
 
@@ -115,10 +116,36 @@ func Test2DecodeChecksum(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	expected := []byte{0x0, 0x0, 0x36, 0x69, 0x7, 0x17, 0x20, 0x5}
+	expected := uint32(0x3669)
 
-	if !bytes.Equal(cook.checksum, expected) {
-		t.Fatalf("incorrect file checksum\n- %#v\n+ %#v", expected, cook.checksum)
+	if cook.Checksum() != expected {
+		t.Fatalf("incorrect file checksum\n- %#08x\n+ %#08x", expected, cook.Checksum())
+	}
+
+	if !cook.ChecksumValid() {
+		t.Fatalf("incorrect computed checksum\n- %#08x\n+ %#08x", expected, cook.computed)
+	}
+}
+
+func Test2DecodeInvalidChecksum(t *testing.T) {
+	data := append([]byte{}, _test2...)
+	data[len(data)-5]++
+
+	cook := New(bytes.NewReader(data))
+
+	if _, err := cook.Decode(); err != nil {
+		t.Fatal(err)
+	}
+
+	if cook.ChecksumValid() {
+		t.Fatalf("checksum %#08x should not be valid", cook.Checksum())
+	}
+
+	cook = New(bytes.NewReader(data))
+	cook.DisallowInvalidChecksum()
+
+	if _, err := cook.Decode(); !errors.Is(err, ErrChecksum) {
+		t.Fatalf("invalid checksum should return an error\n- %s\n+ %v", ErrChecksum, err)
 	}
 }
 
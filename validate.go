@@ -0,0 +1,353 @@
+package binarycookies
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// Severity represents how serious a Finding is.
+type Severity int
+
+const (
+	// SeverityWarning means the file can be decoded but it differs from the
+	// files written by WebKit, for example, unused bytes between cookies or an
+	// invalid checksum.
+	SeverityWarning Severity = iota + 1
+	// SeverityError means the file violates the format and Decode either
+	// fails or returns data that does not correspond to the original cookies.
+	SeverityError
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	}
+
+	return fmt.Sprintf("Severity(%d)", int(s))
+}
+
+// Finding is a single violation of the file format found by Validate. The
+// fields have the same meaning as in DecodeError, and Err can be compared to
+// the sentinel errors with errors.Is.
+type Finding struct {
+	Severity Severity
+	Field    string
+	Page     int
+	Cookie   int
+	Offset   int64
+	Err      error
+}
+
+func (f Finding) String() string {
+	e := DecodeError{Field: f.Field, Page: f.Page, Cookie: f.Cookie, Offset: f.Offset, Err: f.Err}
+
+	return f.Severity.String() + ": " + e.Error()
+}
+
+// validator keeps the findings and the position of the record being checked.
+type validator struct {
+	data     []byte
+	findings []Finding
+	page     int
+	cookie   int
+}
+
+// Validate checks the entire file and returns every violation of the format,
+// in the order they appear in the file. Unlike Decode, which stops at the first
+// error, Validate checks every page and every cookie that can be located with
+// the page size table and the cookie offsets. An empty list means the file is
+// valid.
+//
+// The checks include the signature, the page size table against the length of
+// each page, the page tags and page end markers, the cookie offsets against the
+// position of each cookie, the order of the cookie strings and their null
+// terminators, the cookie end headers, the timestamps, the checksum, the footer
+// and the binary property list after the footer.
+func Validate(data []byte) []Finding {
+	v := &validator{data: data, page: -1, cookie: -1}
+
+	v.validate()
+
+	return v.findings
+}
+
+// report adds one finding for the current page and cookie.
+func (v *validator) report(severity Severity, field string, offset int64, err error) {
+	v.findings = append(v.findings, Finding{
+		Severity: severity,
+		Field:    field,
+		Page:     v.page,
+		Cookie:   v.cookie,
+		Offset:   offset,
+		Err:      err,
+	})
+}
+
+// uint32At returns the 4-bytes integer at the given offset in data, or reports
+// the field as truncated if data is too short.
+func (v *validator) uint32At(data []byte, pos int64, offset int, order binary.ByteOrder, field string) (uint32, bool) {
+	if offset < 0 || offset+4 > len(data) {
+		v.report(SeverityError, field, pos+int64(offset), ErrTruncated)
+		return 0, false
+	}
+
+	return order.Uint32(data[offset:]), true
+}
+
+func (v *validator) validate() {
+	if len(v.data) < 4 {
+		v.report(SeverityError, "signature", 0, ErrTruncated)
+		return
+	}
+
+	if !bytes.Equal(v.data[:4], magic) {
+		v.report(SeverityError, "signature", 0, ErrBadSignature)
+	}
+
+	count, ok := v.uint32At(v.data, 0, 4, binary.BigEndian, "page count")
+
+	if !ok {
+		return
+	}
+
+	// NOTES(cixtor): the page count cannot be trusted, the table is checked
+	// against the file size before reading any of its entries.
+	table := int64(8) + 4*int64(count)
+
+	if table > int64(len(v.data)) {
+		v.report(SeverityError, "page size", 8, fmt.Errorf("table of %d pages beyond file size %d; %w", count, len(v.data), ErrTruncated))
+		return
+	}
+
+	pos := table
+	pages := make([][]byte, 0, count)
+
+	for i := 0; i < int(count); i++ {
+		size := int64(binary.BigEndian.Uint32(v.data[8+4*i:]))
+
+		v.page = i
+
+		if pos+size > int64(len(v.data)) {
+			v.report(SeverityError, "page size", int64(8+4*i), fmt.Errorf("page ends at %d beyond file size %d; %w", pos+size, len(v.data), ErrTruncated))
+			v.validatePage(v.data[pos:], pos)
+			return
+		}
+
+		page := v.data[pos : pos+size]
+		pages = append(pages, page)
+
+		v.validatePage(page, pos)
+
+		pos += size
+	}
+
+	v.page = -1
+
+	v.validateFooter(pages, pos)
+}
+
+// validatePage checks the header of one page and every cookie it contains.
+func (v *validator) validatePage(page []byte, pos int64) {
+	defer func() { v.cookie = -1 }()
+
+	tag, ok := v.uint32At(page, pos, 0, binary.BigEndian, "page tag")
+
+	if !ok {
+		return
+	}
+
+	if tag != binary.BigEndian.Uint32(pageTag) {
+		v.report(SeverityError, "page tag", pos, ErrBadPageTag)
+	}
+
+	count, ok := v.uint32At(page, pos, 4, binary.LittleEndian, "cookie count")
+
+	if !ok {
+		return
+	}
+
+	header := int64(12) + 4*int64(count)
+
+	if header > int64(len(page)) {
+		v.report(SeverityError, "cookie count", pos+4, fmt.Errorf("%d cookies in %d bytes; %w", count, len(page), ErrTruncated))
+		return
+	}
+
+	if end, _ := v.uint32At(page, pos, int(header)-4, binary.LittleEndian, "page end"); end != 0 {
+		v.report(SeverityError, "page end", pos+header-4, ErrBadPageEnd)
+	}
+
+	used := header
+
+	for i := 0; i < int(count); i++ {
+		offset := int64(binary.LittleEndian.Uint32(page[8+4*i:]))
+
+		v.cookie = i
+
+		if offset < header || offset >= int64(len(page)) {
+			v.report(SeverityError, "cookie offset", pos+int64(8+4*i), fmt.Errorf("%d out of page range [%d, %d); %w", offset, header, len(page), ErrBadOffset))
+			continue
+		}
+
+		if offset < used {
+			v.report(SeverityError, "cookie offset", pos+int64(8+4*i), fmt.Errorf("%d overlaps previous cookie ending at %d; %w", offset, used, ErrBadOffset))
+		} else if offset > used {
+			v.report(SeverityWarning, "cookie slack", pos+used, fmt.Errorf("%d unused bytes before the cookie", offset-used))
+		}
+
+		size, ok := v.validateCookie(page[offset:], pos+offset)
+
+		if !ok {
+			// NOTES(cixtor): the end of the cookie is unknown, the rest of the
+			// page cannot be checked for unused bytes.
+			used = int64(len(page))
+			continue
+		}
+
+		used = offset + int64(size)
+	}
+
+	v.cookie = -1
+
+	if used < int64(len(page)) {
+		v.report(SeverityWarning, "page slack", pos+used, fmt.Errorf("%d unused bytes at the end of the page", int64(len(page))-used))
+	}
+}
+
+// validateCookie checks one cookie, data starts with the cookie and ends with
+// the page. It returns the size of the cookie and whether the size is valid.
+func (v *validator) validateCookie(data []byte, pos int64) (uint32, bool) {
+	if len(data) < cookieHeaderSize {
+		v.report(SeverityError, "cookie", pos, fmt.Errorf("header needs %d bytes, %d left in the page; %w", cookieHeaderSize, len(data), ErrTruncated))
+		return 0, false
+	}
+
+	cookie := Cookie{
+		Size:          binary.LittleEndian.Uint32(data[0:]),
+		DomainOffset:  binary.LittleEndian.Uint32(data[16:]),
+		NameOffset:    binary.LittleEndian.Uint32(data[20:]),
+		PathOffset:    binary.LittleEndian.Uint32(data[24:]),
+		ValueOffset:   binary.LittleEndian.Uint32(data[28:]),
+		CommentOffset: binary.LittleEndian.Uint32(data[32:]),
+	}
+
+	if !bytes.Equal(data[36:40], []byte{0x0, 0x0, 0x0, 0x0}) {
+		v.report(SeverityError, "end header", pos+36, ErrBadEndHeader)
+	}
+
+	expires, errExpires := decodeTime(math.Float64frombits(binary.LittleEndian.Uint64(data[40:])))
+	creation, errCreation := decodeTime(math.Float64frombits(binary.LittleEndian.Uint64(data[48:])))
+
+	if errExpires != nil {
+		v.report(SeverityError, "expires", pos+40, errExpires)
+	}
+
+	if errCreation != nil {
+		v.report(SeverityError, "creation", pos+48, errCreation)
+	}
+
+	if errExpires == nil && errCreation == nil && creation.After(expires) {
+		v.report(SeverityWarning, "creation", pos+48, fmt.Errorf("created at %s after expiration at %s", creation, expires))
+	}
+
+	sizeOk := cookie.Size >= cookieHeaderSize && int64(cookie.Size) <= int64(len(data))
+
+	if !sizeOk {
+		v.report(SeverityError, "cookie size", pos, fmt.Errorf("%d out of range [%d, %d]; %w", cookie.Size, cookieHeaderSize, len(data), ErrBadOffset))
+	}
+
+	dec := New(nil)
+
+	if err := dec.checkCookieOffsets(&cookie); err != nil {
+		v.report(SeverityError, "cookie", pos, err)
+		return cookie.Size, sizeOk
+	}
+
+	first := cookie.DomainOffset
+
+	if cookie.CommentOffset != 0 {
+		first = cookie.CommentOffset
+	}
+
+	// NOTES(cixtor): the strings are read one after the other right after the
+	// header, so the offset of the first string must point to the end of it.
+	if first != cookieHeaderSize {
+		v.report(SeverityError, "cookie", pos, fmt.Errorf("first string at %d instead of %d; %w", first, cookieHeaderSize, ErrBadOffset))
+	}
+
+	if err := dec.checkCookieOverallSize(&cookie); err != nil {
+		v.report(SeverityError, "cookie", pos, err)
+	}
+
+	if !sizeOk {
+		return cookie.Size, false
+	}
+
+	for _, f := range cookieStrings(&cookie) {
+		if data[f.end-1] != 0x0 {
+			v.report(SeverityWarning, f.field, pos+int64(f.end-1), ErrNoTerminator)
+		}
+	}
+
+	return cookie.Size, true
+}
+
+// cookieString is the name of a cookie string and the position right after
+// its null terminator, relative to the beginning of the cookie.
+type cookieString struct {
+	field string
+	end   uint32
+}
+
+// cookieStrings returns the strings of the cookie according to its offsets.
+// The comment is only included if the comment offset is different than zero.
+func cookieStrings(cookie *Cookie) []cookieString {
+	fields := []cookieString{
+		{"comment", cookie.DomainOffset},
+		{"domain", cookie.NameOffset},
+		{"name", cookie.PathOffset},
+		{"path", cookie.ValueOffset},
+		{"value", cookie.Size},
+	}
+
+	if cookie.CommentOffset == 0 {
+		return fields[1:]
+	}
+
+	return fields
+}
+
+// validateFooter checks the checksum, the footer and the trailer.
+func (v *validator) validateFooter(pages [][]byte, pos int64) {
+	stored, ok := v.uint32At(v.data, 0, int(pos), binary.BigEndian, "checksum")
+
+	if !ok {
+		return
+	}
+
+	if computed := checksum(pages); stored != computed {
+		v.report(SeverityWarning, "checksum", pos, fmt.Errorf("%#08x != %#08x; %w", stored, computed, ErrChecksum))
+	}
+
+	if _, ok := v.uint32At(v.data, 0, int(pos)+4, binary.BigEndian, "footer"); !ok {
+		return
+	}
+
+	if !bytes.Equal(v.data[pos+4:pos+8], footer) {
+		v.report(SeverityError, "footer", pos+4, ErrBadFooter)
+	}
+
+	dec := New(nil)
+	dec.trailer = v.data[pos+8:]
+	dec.offset = int64(len(v.data))
+
+	if err := dec.readPolicy(); err != nil {
+		e := err.(*DecodeError)
+		v.report(SeverityError, e.Field, e.Offset, e.Err)
+	}
+}
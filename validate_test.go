@@ -0,0 +1,106 @@
+package binarycookies
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func checkFinding(t *testing.T, findings []Finding, severity Severity, target error, expected Finding) {
+	for _, f := range findings {
+		if f.Field == expected.Field && f.Page == expected.Page && f.Cookie == expected.Cookie && f.Offset == expected.Offset {
+			if f.Severity != severity || (target != nil && !errors.Is(f.Err, target)) {
+				t.Fatalf("incorrect finding\n- %s: %s\n+ %s", severity, target, f)
+			}
+
+			return
+		}
+	}
+
+	t.Fatalf("missing finding %s %q page #%d cookie #%d at offset %d in %v",
+		severity, expected.Field, expected.Page, expected.Cookie, expected.Offset, findings)
+}
+
+func TestValidate(t *testing.T) {
+	for _, data := range [][]byte{_test1, _test2} {
+		if findings := Validate(data); len(findings) != 0 {
+			t.Fatalf("valid file should not have findings, got %v", findings)
+		}
+	}
+}
+
+func TestValidateSignature(t *testing.T) {
+	findings := Validate(_test3)
+
+	checkFinding(t, findings, SeverityError, ErrBadSignature, Finding{Field: "signature", Page: -1, Cookie: -1, Offset: 0})
+	checkFinding(t, findings, SeverityError, ErrTruncated, Finding{Field: "page size", Page: -1, Cookie: -1, Offset: 8})
+}
+
+func TestValidateAllFindings(t *testing.T) {
+	// NOTES(cixtor): corrupt the page tag of page #2, the page end of page #1,
+	// the end header and the expiration time of the second cookie in page #1,
+	// the null terminator of the value of the first cookie in page #1, the
+	// checksum and the footer.
+	data := corrupt(_test1, 466, 0xff)
+	data = corrupt(data, 64+24, 0xff)
+	data = corrupt(data, 185+36, 0xff)
+	data = corrupt(data, 185+46, 0xf0, 0x7f)
+	data = corrupt(data, 92+92, 'x')
+	data = corrupt(data, len(_test1)-79-8, 0xff)
+	data = corrupt(data, len(_test1)-79-4+2, 0xff)
+
+	findings := Validate(data)
+
+	checkFinding(t, findings, SeverityError, ErrBadPageEnd, Finding{Field: "page end", Page: 1, Cookie: -1, Offset: 88})
+	checkFinding(t, findings, SeverityError, ErrBadEndHeader, Finding{Field: "end header", Page: 1, Cookie: 1, Offset: 221})
+	checkFinding(t, findings, SeverityError, ErrBadTime, Finding{Field: "expires", Page: 1, Cookie: 1, Offset: 225})
+	checkFinding(t, findings, SeverityWarning, ErrNoTerminator, Finding{Field: "value", Page: 1, Cookie: 0, Offset: 184})
+	checkFinding(t, findings, SeverityError, ErrBadPageTag, Finding{Field: "page tag", Page: 2, Cookie: -1, Offset: 466})
+	checkFinding(t, findings, SeverityWarning, ErrChecksum, Finding{Field: "checksum", Page: -1, Cookie: -1, Offset: int64(len(_test1) - 79 - 8)})
+	checkFinding(t, findings, SeverityError, ErrBadFooter, Finding{Field: "footer", Page: -1, Cookie: -1, Offset: int64(len(_test1) - 79 - 4)})
+
+	if len(findings) != 7 {
+		t.Fatalf("incorrect number of findings\n- %d\n+ %d: %v", 7, len(findings), findings)
+	}
+}
+
+func TestValidateOffsets(t *testing.T) {
+	// NOTES(cixtor): point the second cookie in page #1 into the first one,
+	// and make the strings of the third cookie overlap.
+	data := corrupt(_test1, 64+12, 100)
+	data = corrupt(data, 284+20, 0x38)
+
+	findings := Validate(data)
+
+	checkFinding(t, findings, SeverityError, ErrBadOffset, Finding{Field: "cookie offset", Page: 1, Cookie: 1, Offset: 76})
+	checkFinding(t, findings, SeverityError, ErrBadOffset, Finding{Field: "cookie", Page: 1, Cookie: 2, Offset: 284})
+}
+
+func TestValidateSlack(t *testing.T) {
+	var buf bytes.Buffer
+
+	pages := []Page{
+		{
+			Cookies: []Cookie{
+				{Domain: []byte(`.example.com`), Name: []byte(`a`), Path: []byte(`/`), Value: []byte(`1`)},
+				{Domain: []byte(`.example.com`), Name: []byte(`b`), Path: []byte(`/`), Value: []byte(`2`), slack: make([]byte, 18)},
+			},
+			slack: make([]byte, 8),
+		},
+	}
+
+	if err := NewEncoder(&buf).Encode(pages); err != nil {
+		t.Fatal(err)
+	}
+
+	// NOTES(cixtor): the page starts at offset 12, the first cookie ends 95
+	// bytes later and the second one ends 188 bytes later.
+	findings := Validate(buf.Bytes())
+
+	checkFinding(t, findings, SeverityWarning, nil, Finding{Field: "cookie slack", Page: 0, Cookie: 1, Offset: 107})
+	checkFinding(t, findings, SeverityWarning, nil, Finding{Field: "page slack", Page: 0, Cookie: -1, Offset: 200})
+
+	if len(findings) != 2 {
+		t.Fatalf("incorrect number of findings\n- %d\n+ %d: %v", 2, len(findings), findings)
+	}
+}